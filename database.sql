@@ -9,7 +9,14 @@
 CREATE TABLE estates (
 	id UUID PRIMARY KEY,
 	width INT NOT NULL CHECK ( width > 0 AND width <= 50000 ),
-	length INT NOT NULL CHECK ( length > 0 AND length <= 50000 )
+	length INT NOT NULL CHECK ( length > 0 AND length <= 50000 ),
+	clearance INT NOT NULL DEFAULT 1 CHECK ( clearance >= 1 AND clearance <= 100 ),
+	look_ahead INT NOT NULL DEFAULT 0 CHECK ( look_ahead >= 0 AND look_ahead <= 50 ),
+	dose_base DOUBLE PRECISION NOT NULL DEFAULT 0.5 CHECK ( dose_base >= 0 ),
+	dose_per_meter DOUBLE PRECISION NOT NULL DEFAULT 0.1 CHECK ( dose_per_meter >= 0 ),
+	max_altitude INT NOT NULL DEFAULT 120 CHECK ( max_altitude >= clearance ),
+	trees_digest BIT(128) NOT NULL DEFAULT B'0'::BIT(128),
+	elevations_digest BIT(128) NOT NULL DEFAULT B'0'::BIT(128)
 );
 
 -- THIS IS QUERY FOR CREATING TREES TABLE
@@ -19,5 +26,196 @@ CREATE TABLE trees (
 	x INT NOT NULL CHECK ( x > 0 ),
 	y INT NOT NULL CHECK ( y > 0 ),
 	height INT NOT NULL CHECK ( height >= 1 AND height <= 30 ),
+	canopy_diameter DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK ( canopy_diameter >= 0 AND canopy_diameter <= 100 ),
 	UNIQUE (estate_id, x, y)
 );
+
+-- THIS IS QUERY FOR CREATING NO FLY ZONES TABLE
+CREATE TABLE no_fly_zones (
+    id UUID PRIMARY KEY,
+    estate_id UUID REFERENCES estates(id) ON DELETE CASCADE,
+	name VARCHAR(255) NOT NULL,
+	area POLYGON NOT NULL
+);
+
+CREATE INDEX no_fly_zones_estate_id_idx ON no_fly_zones (estate_id);
+
+-- THIS IS QUERY FOR CREATING PLOT ELEVATIONS TABLE
+-- The elevation in meters of the terrain of the plots above the datum of the
+-- estate, the plots without one are at the datum.
+CREATE TABLE plot_elevations (
+    estate_id UUID REFERENCES estates(id) ON DELETE CASCADE,
+	x INT NOT NULL CHECK ( x > 0 ),
+	y INT NOT NULL CHECK ( y > 0 ),
+	elevation DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (estate_id, x, y)
+);
+
+-- THIS IS QUERY FOR CREATING DRONE PLANS TABLE
+-- Computed drone plans, the version is the hash of the trees digest, the no-fly
+-- zones, the elevations digest and the parameters the plan was computed from.
+CREATE TABLE drone_plans (
+    estate_id UUID REFERENCES estates(id) ON DELETE CASCADE,
+	version CHAR(64) NOT NULL,
+	plan JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (estate_id, version)
+);
+
+-- THIS IS QUERY FOR BOUNDING THE DRONE PLANS OF AN ESTATE
+-- Every combination of parameters caches its own plan, so only the 16 most
+-- recent plans of an estate are kept when a plan is cached.
+CREATE FUNCTION trim_drone_plans() RETURNS TRIGGER AS $$
+BEGIN
+	DELETE FROM drone_plans WHERE estate_id = NEW.estate_id AND version NOT IN (
+		SELECT version FROM drone_plans WHERE estate_id = NEW.estate_id
+		ORDER BY created_at DESC, version LIMIT 16
+	);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER drone_plans_trim_trigger
+AFTER INSERT ON drone_plans
+FOR EACH ROW EXECUTE FUNCTION trim_drone_plans();
+
+-- THIS IS QUERY FOR KEEPING THE TREES DIGEST OF THE ESTATES UP TO DATE
+-- The digest is the XOR of the md5 of every tree of the estate, updated in
+-- place when a tree is created, updated or deleted. The drone plans of the
+-- estate are invalidated at the same time.
+CREATE FUNCTION tree_digest(tree trees) RETURNS BIT(128) AS $$
+	SELECT ('x' || md5(tree.x || ',' || tree.y || ',' || tree.height ||
+		CASE WHEN tree.canopy_diameter > 0 THEN ',' || tree.canopy_diameter ELSE '' END))::BIT(128);
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION update_trees_digest() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		UPDATE estates SET trees_digest = trees_digest # tree_digest(OLD) WHERE id = OLD.estate_id;
+		DELETE FROM drone_plans WHERE estate_id = OLD.estate_id;
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		UPDATE estates SET trees_digest = trees_digest # tree_digest(NEW) WHERE id = NEW.estate_id;
+		DELETE FROM drone_plans WHERE estate_id = NEW.estate_id;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trees_digest_trigger
+AFTER INSERT OR UPDATE OR DELETE ON trees
+FOR EACH ROW EXECUTE FUNCTION update_trees_digest();
+
+-- THIS IS QUERY FOR INVALIDATING THE DRONE PLANS WHEN NO-FLY ZONES CHANGE
+CREATE FUNCTION invalidate_drone_plans() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		DELETE FROM drone_plans WHERE estate_id = OLD.estate_id;
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		DELETE FROM drone_plans WHERE estate_id = NEW.estate_id;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER no_fly_zones_drone_plans_trigger
+AFTER INSERT OR UPDATE OR DELETE ON no_fly_zones
+FOR EACH ROW EXECUTE FUNCTION invalidate_drone_plans();
+
+-- THIS IS QUERY FOR KEEPING THE ELEVATIONS DIGEST OF THE ESTATES UP TO DATE
+-- The digest is the XOR of the md5 of every plot elevation of the estate like
+-- the trees digest. The elevations are replaced all at once, so the digest is
+-- updated once per statement from the rows it removed and added, and the
+-- drone plans of the estate are invalidated at the same time.
+CREATE FUNCTION plot_elevation_digest(x INT, y INT, elevation DOUBLE PRECISION) RETURNS BIT(128) AS $$
+	SELECT ('x' || md5(x || ',' || y || ',' || elevation))::BIT(128);
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION update_elevations_digest() RETURNS TRIGGER AS $$
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		UPDATE estates SET elevations_digest = elevations_digest # removed_digest.digest
+		FROM (
+			SELECT estate_id, bit_xor(plot_elevation_digest(x, y, elevation)) AS digest FROM removed GROUP BY estate_id
+		) removed_digest WHERE id = removed_digest.estate_id;
+		DELETE FROM drone_plans WHERE estate_id IN (SELECT estate_id FROM removed);
+	END IF;
+	IF TG_OP <> 'DELETE' THEN
+		UPDATE estates SET elevations_digest = elevations_digest # added_digest.digest
+		FROM (
+			SELECT estate_id, bit_xor(plot_elevation_digest(x, y, elevation)) AS digest FROM added GROUP BY estate_id
+		) added_digest WHERE id = added_digest.estate_id;
+		DELETE FROM drone_plans WHERE estate_id IN (SELECT estate_id FROM added);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER plot_elevations_insert_digest_trigger
+AFTER INSERT ON plot_elevations
+REFERENCING NEW TABLE AS added
+FOR EACH STATEMENT EXECUTE FUNCTION update_elevations_digest();
+
+CREATE TRIGGER plot_elevations_update_digest_trigger
+AFTER UPDATE ON plot_elevations
+REFERENCING OLD TABLE AS removed NEW TABLE AS added
+FOR EACH STATEMENT EXECUTE FUNCTION update_elevations_digest();
+
+CREATE TRIGGER plot_elevations_delete_digest_trigger
+AFTER DELETE ON plot_elevations
+REFERENCING OLD TABLE AS removed
+FOR EACH STATEMENT EXECUTE FUNCTION update_elevations_digest();
+
+-- THIS IS QUERY FOR CREATING FLIGHTS TABLE
+CREATE TABLE flights (
+    id UUID PRIMARY KEY,
+    estate_id UUID REFERENCES estates(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX flights_estate_id_idx ON flights (estate_id);
+
+-- THIS IS QUERY FOR CREATING FLIGHT SAMPLES TABLE
+CREATE TABLE flight_samples (
+    flight_id UUID REFERENCES flights(id) ON DELETE CASCADE,
+	seq INT NOT NULL,
+	recorded_at TIMESTAMPTZ NOT NULL,
+	x DOUBLE PRECISION NOT NULL,
+	y DOUBLE PRECISION NOT NULL,
+	altitude DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (flight_id, seq)
+);
+
+-- THIS IS QUERY FOR CREATING DRONES TABLE
+-- The registered drones and their specs, drone plans requested for a drone
+-- take its range, speed, climb rate and payload from here.
+CREATE TABLE drones (
+    id UUID PRIMARY KEY,
+	model VARCHAR(255) NOT NULL,
+	max_range INT NOT NULL CHECK ( max_range > 0 ),
+	cruise_speed DOUBLE PRECISION NOT NULL CHECK ( cruise_speed > 0 ),
+	climb_rate DOUBLE PRECISION NOT NULL CHECK ( climb_rate > 0 ),
+	payload_capacity DOUBLE PRECISION NOT NULL CHECK ( payload_capacity >= 0 ),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- THIS IS QUERY FOR CREATING MISSIONS TABLE
+-- A mission is a drone plan saved to be flown later by a registered drone, with
+-- the parameters and the trees digest it was planned with. Its status moves
+-- from scheduled to in_flight and then to completed or aborted.
+CREATE TABLE missions (
+    id UUID PRIMARY KEY,
+    estate_id UUID REFERENCES estates(id) ON DELETE CASCADE,
+	drone_id UUID NOT NULL REFERENCES drones(id),
+	status VARCHAR(16) NOT NULL DEFAULT 'scheduled' CHECK ( status IN ('scheduled', 'in_flight', 'completed', 'aborted') ),
+	parameters JSONB NOT NULL,
+	trees_digest BIT(128) NOT NULL,
+	version CHAR(64) NOT NULL,
+	plan JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	started_at TIMESTAMPTZ,
+	ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX missions_estate_id_idx ON missions (estate_id, created_at);
@@ -0,0 +1,143 @@
+package droneplan
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrInvalidCamera is returned when a camera has a field of view that is not
+// between 0 and 180 degrees or an overlap that is not between 0 and 100
+// percent.
+var ErrInvalidCamera = errors.New("invalid camera")
+
+// Camera is a camera pointing straight down from the drone.
+type Camera struct {
+	// FieldOfView is the angle in degrees the camera sees across.
+	FieldOfView float64
+	// Overlap is the percentage of an image overlapped by the next image
+	// taken along the route.
+	Overlap float64
+}
+
+// Validate returns ErrInvalidCamera unless the field of view is between 0 and
+// 180 degrees and the overlap is at least 0 and below 100 percent.
+func (camera Camera) Validate() error {
+	if camera.FieldOfView <= 0 || camera.FieldOfView >= 180 || camera.Overlap < 0 || camera.Overlap >= 100 {
+		return ErrInvalidCamera
+	}
+	return nil
+}
+
+// Footprint returns the width in meters of the canopy seen in an image taken
+// at a height in meters above it.
+func (camera Camera) Footprint(height float64) float64 {
+	return 2 * height * math.Tan(camera.FieldOfView/2*math.Pi/180)
+}
+
+// Capture is a point of the route where the camera takes an image, at plot
+// relative coordinates like a FlightSample and an altitude in meters.
+type Capture struct {
+	X        float64
+	Y        float64
+	Altitude float64
+}
+
+// Survey is the photogrammetry flight of the sweep.
+type Survey struct {
+	// Footprint is the width in meters of the canopy seen in an image.
+	Footprint float64
+	// Spacing is the distance in meters flown between two captures.
+	Spacing float64
+	// SideOverlap is the percentage of an image overlapped by the images of
+	// the next line, negative when the lines leave gaps between the images.
+	SideOverlap float64
+	// Images is the number of images taken.
+	Images int
+	// Captures are the points the images are taken at, in flight order.
+	Captures []Capture
+}
+
+// Survey places the captures of the camera along the route of the sweep,
+// the images being taken at the clearance above the canopy. The camera
+// triggers every Spacing meters flown horizontally from the take off plot,
+// and once more above the landing plot. Only the number of images is
+// returned when there are more than limit, and none when no-fly zones cover
+// the whole estate.
+func (p *Planner) Survey(camera Camera, limit int) (Survey, error) {
+	if err := camera.Validate(); err != nil {
+		return Survey{}, err
+	}
+
+	footprint := camera.Footprint(float64(p.clearance))
+	survey := Survey{
+		Footprint:   footprint,
+		Spacing:     footprint * (1 - camera.Overlap/100),
+		SideOverlap: 100 * (1 - PlotSize/footprint),
+	}
+
+	route := p.Route()
+	if len(route) == 0 {
+		return survey, nil
+	}
+	length := 0.0
+	for i := 1; i < len(route); i++ {
+		length += horizontal(route[i-1], route[i])
+	}
+
+	// The last capture on the spacing may fall short of the landing plot.
+	spaced := int(length/survey.Spacing+epsilon) + 1
+	short := length-float64(spaced-1)*survey.Spacing > epsilon
+	survey.Images = spaced
+	if short {
+		survey.Images++
+	}
+	if survey.Images > limit {
+		return survey, nil
+	}
+
+	if length == 0 {
+		survey.Captures = []Capture{capture(route[1], route[1], 0)}
+		return survey, nil
+	}
+
+	survey.Captures = make([]Capture, 0, survey.Images)
+	flown, next := 0.0, 0.0
+	var last Waypoint
+	for i := 1; i < len(route); i++ {
+		from, to := route[i-1], route[i]
+		leg := horizontal(from, to)
+		if leg == 0 {
+			continue
+		}
+
+		for ; next <= flown+leg+epsilon && len(survey.Captures) < spaced; next += survey.Spacing {
+			survey.Captures = append(survey.Captures, capture(from, to, min((next-flown)/leg, 1)))
+		}
+		flown += leg
+		last = to
+	}
+	if short {
+		survey.Captures = append(survey.Captures, capture(last, last, 0))
+	}
+
+	return survey, nil
+}
+
+// epsilon absorbs the rounding of the distances summed along the route.
+const epsilon = 1e-9
+
+// horizontal returns the distance in meters flown horizontally between two
+// waypoints.
+func horizontal(from, to Waypoint) float64 {
+	return PlotSize * math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+}
+
+// capture returns the point at the fraction t of the straight flight between
+// two waypoints.
+func capture(from, to Waypoint, t float64) Capture {
+	return Capture{
+		X:        float64(from.X) + t*float64(to.X-from.X),
+		Y:        float64(from.Y) + t*float64(to.Y-from.Y),
+		Altitude: float64(from.Altitude) + t*float64(to.Altitude-from.Altitude),
+	}
+}
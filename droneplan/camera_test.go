@@ -0,0 +1,116 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSurvey(t *testing.T) {
+	testcases := []struct {
+		name      string
+		estate    repository.Estate
+		trees     []repository.EstateTree
+		camera    Camera
+		limit     int
+		footprint float64
+		images    int
+		captures  []Capture
+		err       error
+	}{
+		{
+			name:   "one image above every plot",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+			camera: Camera{FieldOfView: 90, Overlap: 50},
+			limit:  100,
+			// 20 meters wide at 10 meters above the canopy, one image every 10
+			footprint: 20,
+			images:    5,
+			captures: []Capture{
+				{X: 1, Y: 1, Altitude: 20},
+				{X: 2, Y: 1, Altitude: 20},
+				{X: 3, Y: 1, Altitude: 30},
+				{X: 4, Y: 1, Altitude: 30},
+				{X: 5, Y: 1, Altitude: 20},
+			},
+		},
+		{
+			name:      "landing plot captured off the spacing",
+			estate:    repository.Estate{Length: 5, Width: 1},
+			trees:     []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+			camera:    Camera{FieldOfView: 90, Overlap: 25},
+			limit:     100,
+			footprint: 20,
+			images:    4,
+			captures: []Capture{
+				{X: 1, Y: 1, Altitude: 20},
+				{X: 2.5, Y: 1, Altitude: 30},
+				{X: 4, Y: 1, Altitude: 30},
+				{X: 5, Y: 1, Altitude: 20},
+			},
+		},
+		{
+			name:      "captures continue across the turns",
+			estate:    repository.Estate{Length: 2, Width: 2},
+			camera:    Camera{FieldOfView: 90, Overlap: 0},
+			limit:     100,
+			footprint: 20,
+			images:    3,
+			captures: []Capture{
+				{X: 1, Y: 1, Altitude: 10},
+				{X: 2, Y: 2, Altitude: 10},
+				{X: 1, Y: 2, Altitude: 10},
+			},
+		},
+		{
+			name:      "single plot",
+			estate:    repository.Estate{Length: 1, Width: 1},
+			camera:    Camera{FieldOfView: 90, Overlap: 50},
+			limit:     100,
+			footprint: 20,
+			images:    1,
+			captures:  []Capture{{X: 1, Y: 1, Altitude: 10}},
+		},
+		{
+			name:      "only the count above the limit",
+			estate:    repository.Estate{Length: 5, Width: 1},
+			camera:    Camera{FieldOfView: 90, Overlap: 50},
+			limit:     4,
+			footprint: 20,
+			images:    5,
+		},
+		{
+			name:   "overlap must be below 100",
+			estate: repository.Estate{Length: 5, Width: 1},
+			camera: Camera{FieldOfView: 90, Overlap: 100},
+			err:    ErrInvalidCamera,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:    tc.estate,
+				Trees:     tc.trees,
+				Clearance: 10,
+			})
+
+			survey, err := planner.Survey(tc.camera, tc.limit)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.InDelta(t, tc.footprint, survey.Footprint, 1e-9)
+			require.Equal(t, tc.images, survey.Images)
+			require.Len(t, survey.Captures, len(tc.captures))
+			for i, capture := range tc.captures {
+				require.InDelta(t, capture.X, survey.Captures[i].X, 1e-9)
+				require.InDelta(t, capture.Y, survey.Captures[i].Y, 1e-9)
+				require.InDelta(t, capture.Altitude, survey.Captures[i].Altitude, 1e-9)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package droneplan
+
+import "slices"
+
+// DefaultMaxAltitude is the altitude ceiling in meters of the estates that did
+// not configure one, the height drone regulations usually cap flights at.
+const DefaultMaxAltitude = 120
+
+// CeilingViolation is a plot the drone crosses above the altitude ceiling.
+type CeilingViolation struct {
+	Plot Position
+	// Height is the height in meters of the tallest tree whose canopy reaches
+	// the plot, 0 without one.
+	Height int
+	// Elevation is the altitude of the ground of the plot, rounded to the
+	// meter.
+	Elevation int
+	// Altitude is the altitude the drone crosses the plot at, the elevation
+	// plus the height plus the clearance.
+	Altitude int
+}
+
+// MaxAltitude returns the altitude ceiling, 0 when the planner has none.
+func (p *Planner) MaxAltitude() int {
+	return p.maxAltitude
+}
+
+// CeilingViolations returns the plots with a tree or an elevation the drone
+// crosses above the altitude ceiling, in sweep order. The plots in no-fly
+// zones are left out since the drone never crosses them. The other plots are
+// crossed at the clearance, which callers check against the ceiling once.
+func (p *Planner) CeilingViolations() []CeilingViolation {
+	if p.maxAltitude == 0 {
+		return nil
+	}
+
+	var violations []CeilingViolation
+	p.features(func(plot Position) {
+		if altitude := p.altitude(plot); altitude > p.maxAltitude && !p.restricted(plot) {
+			violations = append(violations, CeilingViolation{
+				Plot:      plot,
+				Height:    p.heights[plot],
+				Elevation: p.ground(plot),
+				Altitude:  altitude,
+			})
+		}
+	})
+	slices.SortFunc(violations, func(a, b CeilingViolation) int {
+		return p.index(a.Plot) - p.index(b.Plot)
+	})
+	return violations
+}
@@ -0,0 +1,64 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViolations(t *testing.T) {
+	estate := repository.Estate{Length: 5, Width: 2}
+	trees := []repository.EstateTree{tree(4, 1, 20), tree(3, 1, 19), tree(2, 2, 15)}
+	elevations := []repository.PlotElevation{
+		{X: 2, Y: 2, Elevation: 5},
+		{X: 5, Y: 2, Elevation: 25},
+	}
+
+	testcases := []struct {
+		name        string
+		maxAltitude int
+		zones       []repository.NoFlyZone
+		expected    []CeilingViolation
+	}{
+		{
+			name: "no ceiling",
+		},
+		{
+			name:        "trees and terrain above the ceiling in sweep order",
+			maxAltitude: 20,
+			expected: []CeilingViolation{
+				{Plot: Position{X: 4, Y: 1}, Height: 20, Altitude: 21},
+				{Plot: Position{X: 5, Y: 2}, Elevation: 25, Altitude: 26},
+				{Plot: Position{X: 2, Y: 2}, Height: 15, Elevation: 5, Altitude: 21},
+			},
+		},
+		{
+			name:        "plots in no-fly zones are not crossed",
+			maxAltitude: 20,
+			zones:       []repository.NoFlyZone{rectangle(4, 1, 5, 2)},
+			expected: []CeilingViolation{
+				{Plot: Position{X: 2, Y: 2}, Height: 15, Elevation: 5, Altitude: 21},
+			},
+		},
+		{
+			name:        "everything below the ceiling",
+			maxAltitude: 30,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:      estate,
+				Trees:       trees,
+				Zones:       tc.zones,
+				Elevations:  elevations,
+				MaxAltitude: tc.maxAltitude,
+			})
+
+			require.Equal(t, tc.maxAltitude, planner.MaxAltitude())
+			require.Equal(t, tc.expected, planner.CeilingViolations())
+		})
+	}
+}
@@ -0,0 +1,572 @@
+// Package droneplan computes the monitoring flight of a drone over an estate.
+//
+// The estate is a grid of 10x10 meter plots, x pointing east and y pointing
+// north. The drone takes off from a corner of the estate, sweeps it line by
+// line in a zigzag or ring by ring in a spiral, keeps a clearance above the
+// tree or the ground of every plot it crosses and lands on the last plot it
+// reaches. A tree tour only flies over the trees instead. With a look-ahead
+// window the drone holds its altitude across dips in the canopy no longer
+// than the window instead of descending and climbing again.
+//
+// Altitudes are in meters above the datum of the terrain elevation, the
+// ground of a plot without elevation data being at 0.
+package droneplan
+
+import (
+	"math"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+)
+
+const (
+	// PlotSize is the length in meters of the edge of a plot.
+	PlotSize = 10
+	// DefaultClearance is the height in meters the drone keeps above the tree
+	// or the ground unless the planner is given another clearance.
+	DefaultClearance = 1
+	// MaxClearance is the highest clearance in meters.
+	MaxClearance = 100
+	// MaxLookAhead is the longest look-ahead window in plots.
+	MaxLookAhead = 50
+	// MaxCanopyDiameter is the widest canopy of a tree in meters.
+	MaxCanopyDiameter = 100
+)
+
+// Position is a plot of the estate.
+type Position struct {
+	X int
+	Y int
+}
+
+// Plan is the result of flying the drone over an estate.
+type Plan struct {
+	// Distance is the total distance in meters flown by the drone.
+	Distance int
+	// Rest is the plot where the drone lands, the zero Position when it does
+	// not take off.
+	Rest Position
+}
+
+// Planner computes the flights of the drone over an estate. A planner is not
+// safe for concurrent use.
+type Planner struct {
+	length int
+	width  int
+	// heights is the height of the tallest tree whose canopy reaches the
+	// plots, the trees planted in them or overhanging from their neighbours.
+	heights map[Position]int
+	// trees holds the plots trees are planted in.
+	trees map[Position]bool
+	// elevations is the altitude of the ground of the plots, rounded to the
+	// meter.
+	elevations map[Position]int
+	// ceiling is the altitude the drone crosses the estate at, above every tree.
+	ceiling int
+	// maxAltitude is the altitude ceiling of the regulations, 0 for none.
+	maxAltitude int
+	clearance   int
+	lookAhead   int
+	strategy    Strategy
+	sweep       Sweep
+	zones       []zone
+	// unreachable holds the plots found enclosed by no-fly zones.
+	unreachable map[Position]bool
+	// canopy indexes the trees once a straight line is flown over them.
+	canopy *canopy
+	// course holds the plots the sweep flies over once found.
+	course *course
+	// obstacles holds the plots of the sweep the no-fly zones cover once
+	// found.
+	obstacles *obstacles
+	// distances holds the lengths of the ways from the pad around the no-fly
+	// zones once found.
+	distances *distances
+}
+
+// NewPlannerOptions is the estate, its trees and the flight parameters a
+// planner is created with.
+type NewPlannerOptions struct {
+	Estate repository.Estate
+	Trees  []repository.EstateTree
+	// Strategy is the pattern flown, Zigzag when empty.
+	Strategy Strategy
+	// Sweep is the start corner and orientation of the pattern, the zero
+	// value sweeps along x from the south-west corner.
+	Sweep Sweep
+	// Zones are the no-fly zones of the estate, the drone flies around them.
+	Zones []repository.NoFlyZone
+	// Elevations are the altitudes of the ground of the plots, the plots
+	// without one are at 0.
+	Elevations []repository.PlotElevation
+	// Clearance is the height in meters the drone keeps above the tree or the
+	// ground, DefaultClearance when 0.
+	Clearance int
+	// LookAhead is the number of plots the drone looks ahead to hold its
+	// altitude across dips, 0 follows the canopy plot by plot.
+	LookAhead int
+	// MaxAltitude is the altitude in meters the drone may not fly above, 0
+	// for no ceiling. Plans are still computed above it, see
+	// CeilingViolations.
+	MaxAltitude int
+}
+
+func NewPlanner(opts NewPlannerOptions) *Planner {
+	heights := make(map[Position]int, len(opts.Trees))
+	trees := make(map[Position]bool, len(opts.Trees))
+	for _, tree := range opts.Trees {
+		trees[Position{X: tree.X, Y: tree.Y}] = true
+		overhang(tree, opts.Estate, func(plot Position) {
+			heights[plot] = max(heights[plot], tree.Height)
+		})
+	}
+
+	elevations := make(map[Position]int, len(opts.Elevations))
+	for _, elevation := range opts.Elevations {
+		elevations[Position{X: elevation.X, Y: elevation.Y}] = int(math.Round(elevation.Elevation))
+	}
+
+	clearance := opts.Clearance
+	if clearance == 0 {
+		clearance = DefaultClearance
+	}
+
+	sweep := opts.Sweep
+	if sweep.Corner == "" {
+		sweep.Corner = SouthWest
+	}
+	if sweep.Orientation == "" {
+		sweep.Orientation = AlongX
+	}
+
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = Zigzag
+	}
+
+	zones := make([]zone, 0, len(opts.Zones))
+	for _, z := range opts.Zones {
+		zones = append(zones, zone(z.Vertices))
+	}
+
+	p := &Planner{
+		length:      opts.Estate.Length,
+		width:       opts.Estate.Width,
+		heights:     heights,
+		trees:       trees,
+		elevations:  elevations,
+		ceiling:     clearance,
+		maxAltitude: opts.MaxAltitude,
+		clearance:   clearance,
+		lookAhead:   min(opts.LookAhead, MaxLookAhead),
+		strategy:    strategy,
+		sweep:       sweep,
+		zones:       zones,
+		unreachable: make(map[Position]bool),
+	}
+	p.features(func(plot Position) {
+		p.ceiling = max(p.ceiling, p.altitude(plot))
+	})
+	return p
+}
+
+// Sweep returns the start corner and orientation of the pattern flown by the
+// planner.
+func (p *Planner) Sweep() Sweep {
+	return p.sweep
+}
+
+// Strategy returns the pattern flown by the planner.
+func (p *Planner) Strategy() Strategy {
+	return p.strategy
+}
+
+// Compute flies the drone over the estate and returns its plan. When
+// maxDistance is greater than 0 the drone stops and rests at the last plot it
+// can reach within that distance, otherwise it sweeps the whole estate. The
+// plan is empty when no-fly zones cover the whole estate.
+func (p *Planner) Compute(maxDistance int) Plan {
+	plan, _ := p.compute(maxDistance)
+	return plan
+}
+
+// legs is the distance of a flight split by direction, and the number of plots
+// surveyed.
+type legs struct {
+	horizontal int
+	climb      int
+	descent    int
+	plots      int
+}
+
+// compute flies the drone like Compute and also returns the legs of the
+// flight.
+func (p *Planner) compute(maxDistance int) (Plan, legs) {
+	if p.grounded() {
+		return Plan{}, legs{}
+	}
+	if p.strategy == TreeTour {
+		plan, flight, _ := p.tour(maxDistance)
+		return plan, flight
+	}
+
+	var plan Plan
+	var flight legs
+	fly := func(horizontal, vertical int) bool {
+		cost := horizontal + abs(vertical)
+		if maxDistance > 0 && plan.Distance+cost > maxDistance {
+			return false
+		}
+		plan.Distance += cost
+		flight.horizontal += horizontal
+		if vertical > 0 {
+			flight.climb += vertical
+		} else {
+			flight.descent -= vertical
+		}
+		return true
+	}
+
+	plan.Rest = p.start()
+	if !fly(0, p.altitude(plan.Rest)-p.ground(plan.Rest)) {
+		return plan, flight
+	}
+	flight.plots++
+
+	landing := p.altitude(plan.Rest) - p.ground(plan.Rest)
+	completed := p.glide(func(from, to hover, steps int) bool {
+		if !fly(PlotSize*steps, to.altitude-from.altitude) {
+			// A level stretch still reaches the plots within the distance.
+			if reach := (maxDistance - plan.Distance) / PlotSize; steps > 1 && reach > 0 {
+				fly(PlotSize*reach, 0)
+				plan.Rest = p.position(p.index(from.plot) + reach)
+				flight.plots += reach
+			}
+			return false
+		}
+		plan.Rest, landing = to.plot, to.altitude-p.ground(to.plot)
+		flight.plots += steps
+		return true
+	})
+	if completed {
+		fly(0, -landing)
+	}
+
+	return plan, flight
+}
+
+// Waypoint is a point of the drone route, at an altitude in meters above a
+// plot, with the distance in meters flown since take off.
+type Waypoint struct {
+	X        int
+	Y        int
+	Altitude int
+	Distance int
+}
+
+// Route returns the waypoints the drone flies through to sweep the whole
+// estate, from take off to landing. The drone climbs before moving towards a
+// higher plot and descends after reaching a lower one, so it never flies
+// lower than the clearance. Only the points where the drone changes direction
+// are returned, none when no-fly zones cover the whole estate.
+func (p *Planner) Route() []Waypoint {
+	if p.grounded() {
+		return nil
+	}
+	if p.strategy == TreeTour {
+		_, _, waypoints := p.tour(0)
+		return waypoints
+	}
+
+	start := p.start()
+	r := &route{waypoints: []Waypoint{{X: start.X, Y: start.Y, Altitude: p.ground(start)}}}
+	r.fly(start, p.altitude(start))
+
+	p.glide(func(from, to hover, steps int) bool {
+		// A level stretch turns at every corner of the sweep it crosses.
+		for end := p.turn(p.index(from.plot)); steps > 1 && end < p.index(to.plot); end = p.turn(end + 1) {
+			r.fly(p.position(end), from.altitude)
+			r.fly(p.position(end+1), from.altitude)
+		}
+
+		if to.altitude > from.altitude {
+			r.fly(from.plot, to.altitude)
+			r.fly(to.plot, to.altitude)
+		} else {
+			r.fly(to.plot, from.altitude)
+			r.fly(to.plot, to.altitude)
+		}
+		return true
+	})
+
+	last := r.waypoints[len(r.waypoints)-1]
+	r.fly(Position{X: last.X, Y: last.Y}, p.ground(Position{X: last.X, Y: last.Y}))
+
+	return r.waypoints
+}
+
+// route accumulates the waypoints of a flight, merging the ones the drone
+// flies straight through.
+type route struct {
+	waypoints []Waypoint
+	// distance is the exact distance flown, waypoints hold it rounded to the
+	// meter since diagonal moves are not whole meters.
+	distance float64
+}
+
+// fly moves the drone from the last waypoint to the plot p at the altitude.
+func (r *route) fly(p Position, altitude int) {
+	last := r.waypoints[len(r.waypoints)-1]
+	if last.X == p.X && last.Y == p.Y && last.Altitude == altitude {
+		return
+	}
+
+	r.distance += PlotSize*math.Hypot(float64(p.X-last.X), float64(p.Y-last.Y)) + float64(abs(altitude-last.Altitude))
+	next := Waypoint{
+		X:        p.X,
+		Y:        p.Y,
+		Altitude: altitude,
+		Distance: int(math.Round(r.distance)),
+	}
+
+	n := len(r.waypoints)
+	if n > 1 && direction(r.waypoints[n-2], last) == direction(last, next) {
+		r.waypoints[n-1] = next
+		return
+	}
+	r.waypoints = append(r.waypoints, next)
+}
+
+// direction returns the smallest integer vector of the move between two
+// waypoints, the same for every move along a straight line.
+func direction(from, to Waypoint) [3]int {
+	move := [3]int{to.X - from.X, to.Y - from.Y, to.Altitude - from.Altitude}
+	divisor := gcd(gcd(abs(move[0]), abs(move[1])), abs(move[2]))
+	for i := range move {
+		move[i] /= divisor
+	}
+	return move
+}
+
+// altitude returns the altitude the drone flies at above the plot when
+// following the canopy plot by plot.
+func (p *Planner) altitude(plot Position) int {
+	return p.elevations[plot] + p.heights[plot] + p.clearance
+}
+
+// overhang calls visit for the plot of the tree and every plot of the estate
+// its canopy reaches. The canopy is a disc centered on the plot of the tree,
+// reaching the plots it overlaps.
+func overhang(tree repository.EstateTree, estate repository.Estate, visit func(plot Position)) {
+	visit(Position{X: tree.X, Y: tree.Y})
+
+	radius := min(tree.CanopyDiameter, MaxCanopyDiameter) / 2
+	reach := int(math.Ceil(radius/PlotSize - 0.5))
+	for dy := -reach; dy <= reach; dy++ {
+		for dx := -reach; dx <= reach; dx++ {
+			plot := Position{X: tree.X + dx, Y: tree.Y + dy}
+			if (dx == 0 && dy == 0) || plot.X < 1 || plot.X > estate.Length || plot.Y < 1 || plot.Y > estate.Width {
+				continue
+			}
+
+			// The nearest point of the plot to the trunk.
+			nearX := PlotSize * math.Max(math.Abs(float64(dx))-0.5, 0)
+			nearY := PlotSize * math.Max(math.Abs(float64(dy))-0.5, 0)
+			if math.Hypot(nearX, nearY) < radius {
+				visit(plot)
+			}
+		}
+	}
+}
+
+// ground returns the altitude of the ground of the plot.
+func (p *Planner) ground(plot Position) int {
+	return p.elevations[plot]
+}
+
+// features calls visit for every plot with a tree or an elevation, the only
+// plots the drone may cross above or below the clearance.
+func (p *Planner) features(visit func(plot Position)) {
+	for plot := range p.heights {
+		visit(plot)
+	}
+	for plot := range p.elevations {
+		if _, ok := p.heights[plot]; !ok {
+			visit(plot)
+		}
+	}
+}
+
+// hover is a plot of the sweep and the altitude the drone crosses it at.
+type hover struct {
+	plot     Position
+	altitude int
+}
+
+// ferry returns the distance to fly from the ground of the pad to a plot of
+// the sweep, reaching it at its altitude. Away from the pad the drone climbs
+// to the ceiling and flies along the grid axes, or around the no-fly zones in
+// the way, so it never has to avoid a tree. The zones must not enclose the
+// plot away from the pad.
+func (p *Planner) ferry(pad Position, to hover) int {
+	horizontal := PlotSize * manhattan(pad, to.plot)
+	if horizontal > 0 && len(p.zones) > 0 {
+		horizontal = PlotSize * p.distancesFrom(pad).to(p, to.plot)
+	}
+	if horizontal == 0 {
+		return to.altitude - p.ground(pad)
+	}
+	return p.ceiling - p.ground(pad) + horizontal + p.ceiling - to.altitude
+}
+
+// skippedCount returns the number of plots in the runs Skipped returns.
+func (p *Planner) skippedCount() int {
+	if len(p.zones) == 0 {
+		return 0
+	}
+	if p.strategy == TreeTour {
+		_, skipped := p.stops()
+		return len(skipped)
+	}
+
+	count := 0
+	for _, run := range p.sweepCourse().skipped {
+		count += run[1] - run[0] + 1
+	}
+	return count
+}
+
+// Skipped returns the runs of plots the drone does not survey, because they
+// are in a no-fly zone or enclosed by no-fly zones, in sweep order. A run is
+// split at every turn of the sweep, and is a single tree of a tree tour, so
+// they are found in time proportional to the lines of the sweep instead of
+// its plots.
+func (p *Planner) Skipped() []Run {
+	var skipped []Run
+	if len(p.zones) == 0 {
+		return skipped
+	}
+	if p.strategy == TreeTour {
+		_, trees := p.stops()
+		for _, plot := range trees {
+			skipped = append(skipped, Run{Start: plot, End: plot})
+		}
+		return skipped
+	}
+
+	runs := p.sweepCourse().skipped
+	p.runs(func(index, line, step, lineMove, stepMove, plots int) bool {
+		for len(runs) > 0 && runs[0][0] < index+plots {
+			first, last := max(runs[0][0], index), min(runs[0][1], index+plots-1)
+			skipped = append(skipped, Run{Start: p.position(first), End: p.position(last)})
+			if runs[0][1] > last {
+				break
+			}
+			runs = runs[1:]
+		}
+		return len(runs) > 0
+	})
+	return skipped
+}
+
+// lines returns the number of lines of the sweep and of plots in every line.
+func (p *Planner) lines() (lines, plots int) {
+	if p.sweep.Orientation == AlongY {
+		return p.length, p.width
+	}
+	return p.width, p.length
+}
+
+// start returns the plot the drone takes off from, the first plot of the
+// sweep outside the no-fly zones.
+func (p *Planner) start() Position {
+	return p.sweepCourse().start
+}
+
+// grounded reports whether the no-fly zones cover the whole estate, leaving
+// the drone no plot to take off from.
+func (p *Planner) grounded() bool {
+	return p.sweepCourse().plots == 0
+}
+
+// at returns the plot reached at the step of a line, the zigzag turning
+// back on every other line.
+func (p *Planner) at(line, step int) Position {
+	_, plots := p.lines()
+	if line%2 == 1 {
+		return p.plot(line, plots-1-step)
+	}
+	return p.plot(line, step)
+}
+
+// line returns the line of the sweep the plot belongs to.
+func (p *Planner) line(plot Position) int {
+	if p.sweep.Orientation == AlongY {
+		if p.sweep.Corner == SouthEast || p.sweep.Corner == NorthEast {
+			return p.length - plot.X
+		}
+		return plot.X - 1
+	}
+
+	if p.sweep.Corner == NorthWest || p.sweep.Corner == NorthEast {
+		return p.width - plot.Y
+	}
+	return plot.Y - 1
+}
+
+// step returns the number of plots before the plot in its line, counted from
+// the start corner.
+func (p *Planner) step(plot Position) int {
+	if p.sweep.Orientation == AlongY {
+		if p.sweep.Corner == NorthWest || p.sweep.Corner == NorthEast {
+			return p.width - plot.Y
+		}
+		return plot.Y - 1
+	}
+
+	if p.sweep.Corner == SouthEast || p.sweep.Corner == NorthEast {
+		return p.length - plot.X
+	}
+	return plot.X - 1
+}
+
+// plot returns the position of the index-th plot of a line of the sweep,
+// counted from the start corner.
+func (p *Planner) plot(line, index int) Position {
+	x, y := index, line
+	if p.sweep.Orientation == AlongY {
+		x, y = line, index
+	}
+
+	if p.sweep.Corner == SouthEast || p.sweep.Corner == NorthEast {
+		x = p.length - 1 - x
+	}
+	if p.sweep.Corner == NorthWest || p.sweep.Corner == NorthEast {
+		y = p.width - 1 - y
+	}
+
+	return Position{X: x + 1, Y: y + 1}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,313 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func tree(x, y, height int) repository.EstateTree {
+	return repository.EstateTree{X: x, Y: y, Height: height}
+}
+
+func TestCompute(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+
+	testcases := []struct {
+		name        string
+		estate      repository.Estate
+		trees       []repository.EstateTree
+		maxDistance int
+		expected    Plan
+	}{
+		{
+			name:     "single plot without tree",
+			estate:   repository.Estate{Length: 1, Width: 1},
+			expected: Plan{Distance: 2, Rest: Position{X: 1, Y: 1}},
+		},
+		{
+			name:     "single plot with tree",
+			estate:   repository.Estate{Length: 1, Width: 1},
+			trees:    []repository.EstateTree{tree(1, 1, 5)},
+			expected: Plan{Distance: 12, Rest: Position{X: 1, Y: 1}},
+		},
+		{
+			name:     "empty estate sweeps every plot",
+			estate:   repository.Estate{Length: 2, Width: 2},
+			expected: Plan{Distance: 32, Rest: Position{X: 1, Y: 2}},
+		},
+		{
+			name:     "single row with trees",
+			estate:   repository.Estate{Length: 5, Width: 1},
+			trees:    sample,
+			expected: Plan{Distance: 82, Rest: Position{X: 5, Y: 1}},
+		},
+		{
+			name:   "zigzag turns back on even rows",
+			estate: repository.Estate{Length: 3, Width: 2},
+			trees: []repository.EstateTree{
+				tree(3, 1, 4),
+				tree(3, 2, 4),
+				tree(1, 2, 2),
+			},
+			// 1 + 10 + (10+4) + 10 + (10+4) + (10+2) + 3
+			expected: Plan{Distance: 64, Rest: Position{X: 1, Y: 2}},
+		},
+		{
+			name:        "max distance stops before the next plot",
+			estate:      repository.Estate{Length: 5, Width: 1},
+			trees:       sample,
+			maxDistance: 50,
+			expected:    Plan{Distance: 41, Rest: Position{X: 3, Y: 1}},
+		},
+		{
+			name:        "max distance longer than the plan",
+			estate:      repository.Estate{Length: 5, Width: 1},
+			trees:       sample,
+			maxDistance: 1000,
+			expected:    Plan{Distance: 82, Rest: Position{X: 5, Y: 1}},
+		},
+		{
+			name:        "max distance too short to land",
+			estate:      repository.Estate{Length: 5, Width: 1},
+			trees:       sample,
+			maxDistance: 81,
+			expected:    Plan{Distance: 81, Rest: Position{X: 5, Y: 1}},
+		},
+		{
+			name:        "max distance too short to take off",
+			estate:      repository.Estate{Length: 1, Width: 1},
+			trees:       []repository.EstateTree{tree(1, 1, 5)},
+			maxDistance: 5,
+			expected:    Plan{Distance: 0, Rest: Position{X: 1, Y: 1}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{Estate: tc.estate, Trees: tc.trees})
+			require.Equal(t, tc.expected, planner.Compute(tc.maxDistance))
+		})
+	}
+}
+
+func TestLookAhead(t *testing.T) {
+	dips := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(4, 1, 10),
+		tree(6, 1, 10),
+	}
+	wide := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(5, 1, 10),
+	}
+
+	testcases := []struct {
+		name        string
+		estate      repository.Estate
+		trees       []repository.EstateTree
+		clearance   int
+		lookAhead   int
+		maxDistance int
+		expected    Plan
+	}{
+		{
+			name:     "follows every dip without look-ahead",
+			estate:   repository.Estate{Length: 7, Width: 1},
+			trees:    dips,
+			expected: Plan{Distance: 122, Rest: Position{X: 7, Y: 1}},
+		},
+		{
+			name:      "holds altitude across short dips",
+			estate:    repository.Estate{Length: 7, Width: 1},
+			trees:     dips,
+			lookAhead: 1,
+			// 1 + 10 up, 50 at 11 meters, 10 down + 1
+			expected: Plan{Distance: 82, Rest: Position{X: 7, Y: 1}},
+		},
+		{
+			name:      "descends into dips longer than the window",
+			estate:    repository.Estate{Length: 6, Width: 1},
+			trees:     wide,
+			lookAhead: 1,
+			expected:  Plan{Distance: 92, Rest: Position{X: 6, Y: 1}},
+		},
+		{
+			name:      "holds altitude across dips as long as the window",
+			estate:    repository.Estate{Length: 6, Width: 1},
+			trees:     wide,
+			lookAhead: 2,
+			expected:  Plan{Distance: 72, Rest: Position{X: 6, Y: 1}},
+		},
+		{
+			name:        "max distance stops over a dip",
+			estate:      repository.Estate{Length: 7, Width: 1},
+			trees:       dips,
+			lookAhead:   1,
+			maxDistance: 40,
+			expected:    Plan{Distance: 31, Rest: Position{X: 3, Y: 1}},
+		},
+		{
+			name:      "higher clearance",
+			estate:    repository.Estate{Length: 5, Width: 1},
+			trees:     []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+			clearance: 3,
+			expected:  Plan{Distance: 86, Rest: Position{X: 5, Y: 1}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:    tc.estate,
+				Trees:     tc.trees,
+				Clearance: tc.clearance,
+				LookAhead: tc.lookAhead,
+			})
+			require.Equal(t, tc.expected, planner.Compute(tc.maxDistance))
+
+			if tc.maxDistance == 0 {
+				route := planner.Route()
+				require.Equal(t, tc.expected.Distance, route[len(route)-1].Distance)
+			}
+		})
+	}
+}
+
+func TestRoute(t *testing.T) {
+	testcases := []struct {
+		name       string
+		estate     repository.Estate
+		trees      []repository.EstateTree
+		elevations []repository.PlotElevation
+		expected   []Waypoint
+	}{
+		{
+			name:   "single plot without tree",
+			estate: repository.Estate{Length: 1, Width: 1},
+			expected: []Waypoint{
+				{X: 1, Y: 1, Altitude: 0, Distance: 0},
+				{X: 1, Y: 1, Altitude: 1, Distance: 1},
+				{X: 1, Y: 1, Altitude: 0, Distance: 2},
+			},
+		},
+		{
+			name:   "straight legs are merged",
+			estate: repository.Estate{Length: 2, Width: 2},
+			expected: []Waypoint{
+				{X: 1, Y: 1, Altitude: 0, Distance: 0},
+				{X: 1, Y: 1, Altitude: 1, Distance: 1},
+				{X: 2, Y: 1, Altitude: 1, Distance: 11},
+				{X: 2, Y: 2, Altitude: 1, Distance: 21},
+				{X: 1, Y: 2, Altitude: 1, Distance: 31},
+				{X: 1, Y: 2, Altitude: 0, Distance: 32},
+			},
+		},
+		{
+			name:   "climbs before and descends after trees",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees: []repository.EstateTree{
+				tree(2, 1, 10),
+				tree(3, 1, 20),
+				tree(4, 1, 10),
+			},
+			expected: []Waypoint{
+				{X: 1, Y: 1, Altitude: 0, Distance: 0},
+				{X: 1, Y: 1, Altitude: 11, Distance: 11},
+				{X: 2, Y: 1, Altitude: 11, Distance: 21},
+				{X: 2, Y: 1, Altitude: 21, Distance: 31},
+				{X: 4, Y: 1, Altitude: 21, Distance: 51},
+				{X: 4, Y: 1, Altitude: 11, Distance: 61},
+				{X: 5, Y: 1, Altitude: 11, Distance: 71},
+				{X: 5, Y: 1, Altitude: 0, Distance: 82},
+			},
+		},
+		{
+			name:   "takes off and lands on the terrain",
+			estate: repository.Estate{Length: 3, Width: 1},
+			trees:  []repository.EstateTree{tree(2, 1, 10)},
+			elevations: []repository.PlotElevation{
+				{X: 2, Y: 1, Elevation: 5},
+				{X: 3, Y: 1, Elevation: 9.6},
+			},
+			expected: []Waypoint{
+				{X: 1, Y: 1, Altitude: 0, Distance: 0},
+				{X: 1, Y: 1, Altitude: 16, Distance: 16},
+				{X: 3, Y: 1, Altitude: 16, Distance: 36},
+				{X: 3, Y: 1, Altitude: 10, Distance: 42},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{Estate: tc.estate, Trees: tc.trees, Elevations: tc.elevations})
+			route := planner.Route()
+			require.Equal(t, tc.expected, route)
+			require.Equal(t, planner.Compute(0).Distance, route[len(route)-1].Distance)
+		})
+	}
+}
+
+func TestCanopy(t *testing.T) {
+	canopy := func(x, y, height int, diameter float64) repository.EstateTree {
+		return repository.EstateTree{X: x, Y: y, Height: height, CanopyDiameter: diameter}
+	}
+
+	testcases := []struct {
+		name     string
+		trees    []repository.EstateTree
+		expected map[Position]int
+	}{
+		{
+			name:     "canopy within its plot",
+			trees:    []repository.EstateTree{canopy(3, 2, 20, 10)},
+			expected: map[Position]int{{X: 3, Y: 2}: 20},
+		},
+		{
+			name:  "canopy reaching the plots alongside",
+			trees: []repository.EstateTree{canopy(3, 2, 20, 11)},
+			expected: map[Position]int{
+				{X: 3, Y: 1}: 20,
+				{X: 2, Y: 2}: 20, {X: 3, Y: 2}: 20, {X: 4, Y: 2}: 20,
+				{X: 3, Y: 3}: 20,
+			},
+		},
+		{
+			name:  "canopy reaching the plots diagonally",
+			trees: []repository.EstateTree{canopy(3, 2, 20, 25), tree(4, 2, 25), tree(5, 2, 10)},
+			expected: map[Position]int{
+				{X: 2, Y: 1}: 20, {X: 3, Y: 1}: 20, {X: 4, Y: 1}: 20,
+				{X: 2, Y: 2}: 20, {X: 3, Y: 2}: 20, {X: 4, Y: 2}: 25, {X: 5, Y: 2}: 10,
+				{X: 2, Y: 3}: 20, {X: 3, Y: 3}: 20, {X: 4, Y: 3}: 20,
+			},
+		},
+		{
+			name:  "canopy cut by the edges of the estate",
+			trees: []repository.EstateTree{canopy(1, 1, 10, 25)},
+			expected: map[Position]int{
+				{X: 1, Y: 1}: 10, {X: 2, Y: 1}: 10,
+				{X: 1, Y: 2}: 10, {X: 2, Y: 2}: 10,
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: repository.Estate{Length: 5, Width: 3},
+				Trees:  tc.trees,
+			})
+			require.Equal(t, tc.expected, planner.heights)
+
+			// The tree tour only stops above the trees planted.
+			stops, _ := planner.stops()
+			require.Len(t, stops, len(tc.trees))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package droneplan
+
+import "errors"
+
+// ErrInvalidPerformance is returned when a drone performance has a speed or
+// rate that is not positive, or a negative hover time or power.
+var ErrInvalidPerformance = errors.New("invalid drone performance")
+
+// Performance is the flight characteristics of a drone.
+type Performance struct {
+	// Speed is the horizontal speed in meters per second.
+	Speed float64
+	// ClimbRate and DescentRate are the vertical speeds in meters per second.
+	ClimbRate   float64
+	DescentRate float64
+	// HoverTime is the time in seconds the drone hovers above every plot it
+	// crosses, to stabilize and survey it.
+	HoverTime float64
+	// Power is the average power in watts the drone draws in flight.
+	Power float64
+}
+
+// DefaultPerformance is the performance of a typical survey multirotor.
+var DefaultPerformance = Performance{
+	Speed:       10,
+	ClimbRate:   3,
+	DescentRate: 2,
+	HoverTime:   0,
+	Power:       250,
+}
+
+// Validate returns ErrInvalidPerformance unless the speed and rates are
+// positive and the hover time and power are not negative.
+func (performance Performance) Validate() error {
+	if performance.Speed <= 0 || performance.ClimbRate <= 0 || performance.DescentRate <= 0 || performance.HoverTime < 0 || performance.Power < 0 {
+		return ErrInvalidPerformance
+	}
+	return nil
+}
+
+// Estimate is the duration and energy of a flight.
+type Estimate struct {
+	// Horizontal, Vertical and Hover are the times in seconds spent flying
+	// horizontally, climbing or descending, and hovering.
+	Horizontal float64
+	Vertical   float64
+	Hover      float64
+	// Duration is the total flight time in seconds.
+	Duration float64
+	// Energy is the energy drawn from the battery in watt hours.
+	Energy float64
+}
+
+// Estimate returns the duration and energy of the flight Compute plans for the
+// same maxDistance. Vertical meters are flown at the climb or descent rate,
+// usually much slower than the horizontal speed.
+func (p *Planner) Estimate(maxDistance int, performance Performance) (Estimate, error) {
+	if err := performance.Validate(); err != nil {
+		return Estimate{}, err
+	}
+
+	_, flight := p.compute(maxDistance)
+
+	estimate := Estimate{
+		Horizontal: float64(flight.horizontal) / performance.Speed,
+		Vertical:   float64(flight.climb)/performance.ClimbRate + float64(flight.descent)/performance.DescentRate,
+		Hover:      float64(flight.plots) * performance.HoverTime,
+	}
+	estimate.Duration = estimate.Horizontal + estimate.Vertical + estimate.Hover
+	estimate.Energy = performance.Power * estimate.Duration / 3600
+	return estimate, nil
+}
@@ -0,0 +1,68 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimate(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+
+	testcases := []struct {
+		name        string
+		maxDistance int
+		performance Performance
+		expected    Estimate
+		err         error
+	}{
+		{
+			name:        "whole sweep",
+			performance: DefaultPerformance,
+			// 40 meters at 10 m/s, 21 meters up at 3 m/s and 21 down at 2 m/s
+			expected: Estimate{Horizontal: 4, Vertical: 17.5, Duration: 21.5, Energy: 250 * 21.5 / 3600},
+		},
+		{
+			name:        "hover above every plot",
+			performance: Performance{Speed: 10, ClimbRate: 3, DescentRate: 2, HoverTime: 2, Power: 360},
+			expected:    Estimate{Horizontal: 4, Vertical: 17.5, Hover: 10, Duration: 31.5, Energy: 3.15},
+		},
+		{
+			name:        "max distance stops before landing",
+			maxDistance: 50,
+			performance: Performance{Speed: 10, ClimbRate: 3, DescentRate: 2, Power: 360},
+			expected:    Estimate{Horizontal: 2, Vertical: 7, Duration: 9, Energy: 0.9},
+		},
+		{
+			name:        "speed must be positive",
+			performance: Performance{ClimbRate: 3, DescentRate: 2},
+			err:         ErrInvalidPerformance,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: repository.Estate{Length: 5, Width: 1},
+				Trees:  sample,
+			})
+
+			estimate, err := planner.Estimate(tc.maxDistance, tc.performance)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.InDelta(t, tc.expected.Horizontal, estimate.Horizontal, 1e-9)
+			require.InDelta(t, tc.expected.Vertical, estimate.Vertical, 1e-9)
+			require.InDelta(t, tc.expected.Hover, estimate.Hover, 1e-9)
+			require.InDelta(t, tc.expected.Duration, estimate.Duration, 1e-9)
+			require.InDelta(t, tc.expected.Energy, estimate.Energy, 1e-9)
+		})
+	}
+}
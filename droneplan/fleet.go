@@ -0,0 +1,181 @@
+package droneplan
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyDrones is returned when a fleet has more drones than the estate
+// has lines to share between them.
+var ErrTooManyDrones = errors.New("too many drones")
+
+// Strip is the part of the estate flown by one drone of a fleet, a rectangle
+// of whole lines of the sweep.
+type Strip struct {
+	// From and To are the south-west and north-east plots of the strip.
+	From Position
+	To   Position
+	// Start is the plot the drone takes off from.
+	Start Position
+	// Plan is the flight of the drone over the strip.
+	Plan Plan
+}
+
+// Partition splits the estate into one strip per drone, each made of
+// consecutive lines of the sweep, so that the longest flight of the fleet is
+// as short as possible. Every drone sweeps its strip from the corner of the
+// strip matching the start corner of the planner. Only zigzags are split.
+func (p *Planner) Partition(drones int) ([]Strip, error) {
+	if p.strategy != Zigzag {
+		return nil, fmt.Errorf("%w: a fleet shares the lines of a zigzag, not a %s", ErrUnsupportedStrategy, p.strategy)
+	}
+
+	lines, _ := p.lines()
+	if drones > lines {
+		return nil, fmt.Errorf("%w: the sweep has only %d lines", ErrTooManyDrones, lines)
+	}
+	if p.grounded() {
+		return nil, ErrEstateInNoFlyZones
+	}
+
+	costs := p.lineCosts()
+	bands := balance(costs, drones)
+
+	strips := make([]Strip, 0, drones)
+	for _, band := range bands {
+		planner, offset := p.band(band[0], band[1])
+		if planner.grounded() {
+			return nil, fmt.Errorf("%w: no-fly zones cover lines %d to %d of the sweep", ErrTooManyDrones, band[0]+1, band[1]+1)
+		}
+		plan := planner.Compute(0)
+		plan.Rest = offset.shift(plan.Rest)
+
+		strips = append(strips, Strip{
+			From:  offset.shift(Position{X: 1, Y: 1}),
+			To:    offset.shift(Position{X: planner.length, Y: planner.width}),
+			Start: offset.shift(planner.start()),
+			Plan:  plan,
+		})
+	}
+
+	return strips, nil
+}
+
+// lineCosts returns the distance flown over every line of the sweep, the
+// flight from the previous line included.
+func (p *Planner) lineCosts() []int {
+	lines, _ := p.lines()
+	costs := make([]int, lines)
+	costs[p.line(p.start())] = p.altitude(p.start()) - p.ground(p.start())
+
+	_, plots := p.lines()
+	p.glide(func(from, to hover, steps int) bool {
+		// Every line crossed by a level stretch costs a step per plot.
+		for next := p.index(to.plot) - steps + 1; steps > 1 && next < p.index(to.plot); {
+			end := min((next/plots+1)*plots-1, p.index(to.plot)-1)
+			costs[next/plots] += PlotSize * (end - next + 1)
+			next = end + 1
+		}
+		costs[p.line(to.plot)] += PlotSize + abs(to.altitude-from.altitude)
+		return true
+	})
+
+	return costs
+}
+
+// band returns the planner of the lines first to last of the sweep, and the
+// offset of its plots in the estate.
+func (p *Planner) band(first, last int) (*Planner, Position) {
+	_, plots := p.lines()
+	a, b := p.plot(first, 0), p.plot(last, plots-1)
+	from := Position{X: min(a.X, b.X), Y: min(a.Y, b.Y)}
+	to := Position{X: max(a.X, b.X), Y: max(a.Y, b.Y)}
+	offset := Position{X: from.X - 1, Y: from.Y - 1}
+
+	band := &Planner{
+		length:      to.X - from.X + 1,
+		width:       to.Y - from.Y + 1,
+		heights:     make(map[Position]int),
+		trees:       make(map[Position]bool),
+		elevations:  make(map[Position]int),
+		ceiling:     p.clearance,
+		clearance:   p.clearance,
+		lookAhead:   p.lookAhead,
+		strategy:    p.strategy,
+		sweep:       p.sweep,
+		zones:       make([]zone, 0, len(p.zones)),
+		unreachable: make(map[Position]bool),
+	}
+	for _, z := range p.zones {
+		band.zones = append(band.zones, z.shift(offset))
+	}
+	p.features(func(plot Position) {
+		if plot.X < from.X || plot.X > to.X || plot.Y < from.Y || plot.Y > to.Y {
+			return
+		}
+		shifted := Position{X: plot.X - offset.X, Y: plot.Y - offset.Y}
+		if height, ok := p.heights[plot]; ok {
+			band.heights[shifted] = height
+		}
+		if p.trees[plot] {
+			band.trees[shifted] = true
+		}
+		if elevation, ok := p.elevations[plot]; ok {
+			band.elevations[shifted] = elevation
+		}
+		band.ceiling = max(band.ceiling, p.altitude(plot))
+	})
+
+	return band, offset
+}
+
+// shift moves the plot by the offset.
+func (offset Position) shift(plot Position) Position {
+	return Position{X: plot.X + offset.X, Y: plot.Y + offset.Y}
+}
+
+// balance splits the costs into the given number of consecutive non empty
+// groups, minimizing the largest sum of a group. It returns the first and last
+// index of every group.
+func balance(costs []int, groups int) [][2]int {
+	low, high := 0, 0
+	for _, cost := range costs {
+		low = max(low, cost)
+		high += cost
+	}
+
+	// The smallest largest sum such that greedy groups fit in the count.
+	for low < high {
+		limit := (low + high) / 2
+		count, sum := 1, 0
+		for _, cost := range costs {
+			if sum+cost > limit {
+				count++
+				sum = 0
+			}
+			sum += cost
+		}
+
+		if count <= groups {
+			high = limit
+		} else {
+			low = limit + 1
+		}
+	}
+
+	bands := make([][2]int, 0, groups)
+	first := 0
+	for group := 0; group < groups; group++ {
+		last, sum := first, costs[first]
+		remaining := groups - group - 1
+		for last+1 < len(costs) && len(costs)-(last+1) > remaining && (remaining == 0 || sum+costs[last+1] <= low) {
+			last++
+			sum += costs[last]
+		}
+
+		bands = append(bands, [2]int{first, last})
+		first = last + 1
+	}
+
+	return bands
+}
@@ -0,0 +1,99 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartition(t *testing.T) {
+	testcases := []struct {
+		name     string
+		opts     NewPlannerOptions
+		drones   int
+		expected []Strip
+		err      error
+	}{
+		{
+			name:   "single drone flies the whole estate",
+			opts:   NewPlannerOptions{Estate: repository.Estate{Length: 4, Width: 4}},
+			drones: 1,
+			expected: []Strip{
+				{From: Position{1, 1}, To: Position{4, 4}, Start: Position{1, 1}, Plan: Plan{Distance: 152, Rest: Position{1, 4}}},
+			},
+		},
+		{
+			name:   "empty estate is split evenly",
+			opts:   NewPlannerOptions{Estate: repository.Estate{Length: 4, Width: 4}},
+			drones: 2,
+			expected: []Strip{
+				{From: Position{1, 1}, To: Position{4, 2}, Start: Position{1, 1}, Plan: Plan{Distance: 72, Rest: Position{1, 2}}},
+				{From: Position{1, 3}, To: Position{4, 4}, Start: Position{1, 3}, Plan: Plan{Distance: 72, Rest: Position{1, 4}}},
+			},
+		},
+		{
+			name: "tall trees shrink the strip",
+			opts: NewPlannerOptions{
+				Estate: repository.Estate{Length: 3, Width: 3},
+				Trees:  []repository.EstateTree{tree(2, 1, 20)},
+			},
+			drones: 2,
+			expected: []Strip{
+				{From: Position{1, 1}, To: Position{3, 1}, Start: Position{1, 1}, Plan: Plan{Distance: 62, Rest: Position{3, 1}}},
+				{From: Position{1, 2}, To: Position{3, 3}, Start: Position{1, 2}, Plan: Plan{Distance: 52, Rest: Position{1, 3}}},
+			},
+		},
+		{
+			name: "strips follow the sweep",
+			opts: NewPlannerOptions{
+				Estate: repository.Estate{Length: 3, Width: 2},
+				Sweep:  Sweep{Corner: NorthEast, Orientation: AlongY},
+			},
+			drones: 3,
+			expected: []Strip{
+				{From: Position{3, 1}, To: Position{3, 2}, Start: Position{3, 2}, Plan: Plan{Distance: 12, Rest: Position{3, 1}}},
+				{From: Position{2, 1}, To: Position{2, 2}, Start: Position{2, 2}, Plan: Plan{Distance: 12, Rest: Position{2, 1}}},
+				{From: Position{1, 1}, To: Position{1, 2}, Start: Position{1, 2}, Plan: Plan{Distance: 12, Rest: Position{1, 1}}},
+			},
+		},
+		{
+			name:   "more drones than lines",
+			opts:   NewPlannerOptions{Estate: repository.Estate{Length: 4, Width: 3}},
+			drones: 4,
+			err:    ErrTooManyDrones,
+		},
+		{
+			name: "zones over a strip",
+			opts: NewPlannerOptions{
+				Estate: repository.Estate{Length: 4, Width: 2},
+				Zones:  []repository.NoFlyZone{rectangle(1, 2, 4, 2)},
+			},
+			drones: 2,
+			err:    ErrTooManyDrones,
+		},
+		{
+			name: "zones over the whole estate",
+			opts: NewPlannerOptions{
+				Estate: repository.Estate{Length: 4, Width: 2},
+				Zones:  []repository.NoFlyZone{rectangle(1, 1, 4, 2)},
+			},
+			drones: 1,
+			err:    ErrEstateInNoFlyZones,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			strips, err := NewPlanner(tc.opts).Partition(tc.drones)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, strips)
+		})
+	}
+}
+
+func TestBalance(t *testing.T) {
+	require.Equal(t, [][2]int{{0, 1}, {2, 2}, {3, 4}}, balance([]int{5, 5, 10, 4, 6}, 3))
+	require.Equal(t, [][2]int{{0, 0}, {1, 1}, {2, 2}}, balance([]int{1, 1, 1}, 3))
+	require.Equal(t, [][2]int{{0, 2}}, balance([]int{1, 1, 1}, 1))
+}
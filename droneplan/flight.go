@@ -0,0 +1,234 @@
+package droneplan
+
+import (
+	"math"
+	"slices"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+)
+
+// Report compares a flight actually flown over the estate with its plan.
+type Report struct {
+	// PlannedPlots is the number of plots the sweep surveys.
+	PlannedPlots int
+	// CoveredPlots is the number of planned plots the drone flew over.
+	CoveredPlots int
+	// Coverage is the percentage of the planned plots covered.
+	Coverage float64
+	// Missed are the runs of planned plots the drone did not fly over, line
+	// by line of the zigzag.
+	Missed []Run
+	// Violations are the samples below the altitude of their plot.
+	Violations []Violation
+	// SegmentViolations are the straight lines between two samples crossing
+	// a plot below its altitude, the plots of the samples aside.
+	SegmentViolations []SegmentViolation
+	// Distance is the distance in meters flown between the samples.
+	Distance float64
+	// PlannedDistance is the distance in meters of the plan.
+	PlannedDistance int
+}
+
+// Run is consecutive plots in a straight line, from the first to the last in
+// flight order.
+type Run struct {
+	Start Position
+	End   Position
+}
+
+// Violation is a sample of a flight below the canopy and clearance of the
+// plot it was recorded above.
+type Violation struct {
+	// Index is the position of the sample in the flight.
+	Index  int
+	Sample repository.FlightSample
+	// Required is the lowest altitude allowed above the plot.
+	Required int
+}
+
+// SegmentViolation is a straight line between two samples of a flight
+// crossing a plot below its canopy and clearance.
+type SegmentViolation struct {
+	// Index is the position in the flight of the sample ending the segment,
+	// the segment starting from the sample before it.
+	Index int
+	// Plot is the plot the segment crosses the furthest below its altitude
+	// and Altitude the altitude of the segment above the center of the plot.
+	Plot     Position
+	Altitude float64
+	// Required is the lowest altitude allowed above the plot.
+	Required int
+}
+
+// Compare checks the samples of a flight, in time order, against the sweep of
+// the estate. The drone covers every plot crossed by the straight line
+// between two samples recorded airborne, above the ground of their plot.
+// Take off and landing are not checked for altitude violations, only the
+// airborne samples from the first to the last one at the altitude of their
+// plot and the straight lines between them over the plots they cross.
+func (p *Planner) Compare(samples []repository.FlightSample) Report {
+	report := Report{PlannedDistance: p.Compute(0).Distance}
+
+	covered := make(map[Position]bool)
+	cover := func(plot Position) {
+		covered[plot] = true
+	}
+	airborne := func(sample repository.FlightSample) bool {
+		return sample.Altitude > float64(p.ground(nearest(sample)))
+	}
+	first, last := -1, -1
+	for i, sample := range samples {
+		plot := nearest(sample)
+		if sample.Altitude >= float64(p.altitude(plot)) {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+
+		if i == 0 {
+			if airborne(sample) {
+				cover(plot)
+			}
+			continue
+		}
+
+		previous := samples[i-1]
+		report.Distance += PlotSize*math.Hypot(sample.X-previous.X, sample.Y-previous.Y) + math.Abs(sample.Altitude-previous.Altitude)
+		if airborne(previous) && airborne(sample) {
+			crossing(nearest(previous), plot, cover)
+		}
+	}
+
+	for i := first; first >= 0 && i <= last; i++ {
+		required := p.altitude(nearest(samples[i]))
+		if airborne(samples[i]) && samples[i].Altitude < float64(required) {
+			report.Violations = append(report.Violations, Violation{Index: i, Sample: samples[i], Required: required})
+		}
+		if i > first && airborne(samples[i-1]) && airborne(samples[i]) {
+			if violation, found := p.segmentViolation(samples[i-1], samples[i]); found {
+				violation.Index = i
+				report.SegmentViolations = append(report.SegmentViolations, violation)
+			}
+		}
+	}
+
+	lines, plots := p.lines()
+	report.PlannedPlots = lines*plots - p.skippedCount()
+
+	// The plots of a line the drone does not survey and the plots it covered
+	// are the holes between the runs of plots it missed.
+	coveredSteps := make(map[int][]int)
+	for plot := range covered {
+		if plot.X >= 1 && plot.X <= p.length && plot.Y >= 1 && plot.Y <= p.width {
+			coveredSteps[p.line(plot)] = append(coveredSteps[p.line(plot)], p.step(plot))
+		}
+	}
+	skipped := p.skippedSteps()
+	for line := 0; line < lines; line++ {
+		var holes [][2]int
+		skipped(line, func(first, last int) {
+			holes = append(holes, [2]int{first, last})
+		})
+		for _, step := range coveredSteps[line] {
+			if !slices.ContainsFunc(holes, func(hole [2]int) bool {
+				return hole[0] <= step && step <= hole[1]
+			}) {
+				report.CoveredPlots++
+			}
+		}
+		for _, step := range coveredSteps[line] {
+			holes = append(holes, [2]int{step, step})
+		}
+		slices.SortFunc(holes, func(a, b [2]int) int {
+			return a[0] - b[0]
+		})
+
+		var missed []Run
+		next := 0
+		for _, hole := range append(holes, [2]int{plots, plots}) {
+			if hole[0] > next {
+				missed = append(missed, Run{Start: p.plot(line, next), End: p.plot(line, hole[0]-1)})
+			}
+			next = max(next, hole[1]+1)
+		}
+		if line%2 == 1 {
+			slices.Reverse(missed)
+			for i := range missed {
+				missed[i].Start, missed[i].End = missed[i].End, missed[i].Start
+			}
+		}
+		report.Missed = append(report.Missed, missed...)
+	}
+	if report.PlannedPlots > 0 {
+		report.Coverage = 100 * float64(report.CoveredPlots) / float64(report.PlannedPlots)
+	}
+
+	return report
+}
+
+// segmentViolation returns the plot the straight line between two samples
+// crosses the furthest below its altitude, if any. The altitude of the line
+// above a plot is the one above the nearest point of the line to its center.
+// The plots of the samples are checked with the samples.
+func (p *Planner) segmentViolation(from, to repository.FlightSample) (SegmentViolation, bool) {
+	var violation SegmentViolation
+	found := false
+
+	dx, dy := to.X-from.X, to.Y-from.Y
+	crossing(nearest(from), nearest(to), func(plot Position) {
+		if plot == nearest(from) || plot == nearest(to) {
+			return
+		}
+
+		t := ((float64(plot.X)-from.X)*dx + (float64(plot.Y)-from.Y)*dy) / (dx*dx + dy*dy)
+		altitude := from.Altitude + min(max(t, 0), 1)*(to.Altitude-from.Altitude)
+		required := p.altitude(plot)
+		if altitude < float64(required) && (!found || float64(required)-altitude > float64(violation.Required)-violation.Altitude) {
+			violation = SegmentViolation{Plot: plot, Altitude: altitude, Required: required}
+			found = true
+		}
+	})
+
+	return violation, found
+}
+
+// nearest returns the plot a sample was recorded above.
+func nearest(sample repository.FlightSample) Position {
+	return Position{X: int(math.Round(sample.X)), Y: int(math.Round(sample.Y))}
+}
+
+// skippedSteps returns the function calling visit for the runs of steps of a
+// line of the sweep the drone does not survey, in order. Without a tree tour
+// they are found around the no-fly zones line by line.
+func (p *Planner) skippedSteps() func(line int, visit func(first, last int)) {
+	if len(p.zones) == 0 {
+		return func(line int, visit func(first, last int)) {}
+	}
+
+	if p.strategy == TreeTour {
+		_, trees := p.stops()
+		skipped := make(map[int][]int)
+		for _, plot := range trees {
+			skipped[p.line(plot)] = append(skipped[p.line(plot)], p.step(plot))
+		}
+		return func(line int, visit func(first, last int)) {
+			steps := slices.Sorted(slices.Values(skipped[line]))
+			for _, step := range steps {
+				visit(step, step)
+			}
+		}
+	}
+
+	_, plots := p.lines()
+	o := p.zoneObstacles()
+	component := -1
+	if start := p.start(); !p.grounded() {
+		component = o.component(p.line(start), p.step(start))
+	}
+	return func(line int, visit func(first, last int)) {
+		o.along(line, 0, plots-1, func(c int) bool {
+			return c < 0 || c != component
+		}, visit)
+	}
+}
@@ -0,0 +1,108 @@
+package droneplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+	planner := NewPlanner(NewPlannerOptions{
+		Estate: repository.Estate{Length: 5, Width: 1},
+		Trees:  sample,
+	})
+
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	log := func(points ...[3]float64) []repository.FlightSample {
+		samples := make([]repository.FlightSample, 0, len(points))
+		for i, point := range points {
+			samples = append(samples, repository.FlightSample{
+				Time:     start.Add(time.Duration(i) * time.Second),
+				X:        point[0],
+				Y:        point[1],
+				Altitude: point[2],
+			})
+		}
+		return samples
+	}
+
+	var planned [][3]float64
+	for _, waypoint := range planner.Route() {
+		planned = append(planned, [3]float64{float64(waypoint.X), float64(waypoint.Y), float64(waypoint.Altitude)})
+	}
+
+	testcases := []struct {
+		name       string
+		samples    []repository.FlightSample
+		coverage   float64
+		missed     []Run
+		violations []Violation
+		distance   float64
+	}{
+		{
+			name:     "flight following the plan",
+			samples:  log(planned...),
+			coverage: 100,
+			distance: 82,
+		},
+		{
+			name:     "flight straight through the tallest tree",
+			samples:  log([3]float64{1, 1, 0}, [3]float64{1, 1, 11}, [3]float64{3, 1, 11}, [3]float64{5, 1, 11}, [3]float64{5, 1, 0}),
+			coverage: 100,
+			violations: []Violation{
+				{Index: 2, Sample: repository.FlightSample{Time: start.Add(2 * time.Second), X: 3, Y: 1, Altitude: 11}, Required: 21},
+			},
+			distance: 62,
+		},
+		{
+			name: "landing halfway misses a plot",
+			samples: log(
+				[3]float64{1, 1, 0}, [3]float64{1, 1, 11}, [3]float64{2, 1, 11}, [3]float64{2, 1, 0},
+				[3]float64{4, 1, 0}, [3]float64{4, 1, 11}, [3]float64{5, 1, 11}, [3]float64{5, 1, 0},
+			),
+			coverage: 80,
+			missed:   []Run{{Start: Position{X: 3, Y: 1}, End: Position{X: 3, Y: 1}}},
+			distance: 11 + 10 + 11 + 20 + 11 + 10 + 11,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := planner.Compare(tc.samples)
+			require.Equal(t, 5, report.PlannedPlots)
+			require.Equal(t, 82, report.PlannedDistance)
+			require.InDelta(t, tc.coverage, report.Coverage, 1e-9)
+			require.Equal(t, tc.missed, report.Missed)
+			require.Equal(t, tc.violations, report.Violations)
+			require.InDelta(t, tc.distance, report.Distance, 1e-9)
+		})
+	}
+}
+
+func TestCompareMissedRuns(t *testing.T) {
+	planner := NewPlanner(NewPlannerOptions{
+		Estate: repository.Estate{Length: 3, Width: 3},
+		Zones:  []repository.NoFlyZone{rectangle(2, 2, 2, 2)},
+	})
+
+	report := planner.Compare([]repository.FlightSample{
+		{X: 1, Y: 1, Altitude: 0},
+		{X: 1, Y: 1, Altitude: 1},
+		{X: 3, Y: 1, Altitude: 1},
+		{X: 3, Y: 1, Altitude: 0},
+	})
+	require.Equal(t, 8, report.PlannedPlots)
+	require.Equal(t, 3, report.CoveredPlots)
+	require.Equal(t, []Run{
+		{Start: Position{X: 3, Y: 2}, End: Position{X: 3, Y: 2}},
+		{Start: Position{X: 1, Y: 2}, End: Position{X: 1, Y: 2}},
+		{Start: Position{X: 1, Y: 3}, End: Position{X: 3, Y: 3}},
+	}, report.Missed)
+}
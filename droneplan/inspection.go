@@ -0,0 +1,284 @@
+package droneplan
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+)
+
+// MaxInspectionTrees is the largest number of trees a single inspection
+// visits, the tour is improved in quadratic time per pass.
+const MaxInspectionTrees = 500
+
+// ErrTooManyTrees is returned when an inspection targets more than
+// MaxInspectionTrees trees.
+var ErrTooManyTrees = errors.New("too many trees to inspect")
+
+// ErrPadInNoFlyZone is returned when the drone would take off from a no-fly
+// zone.
+var ErrPadInNoFlyZone = errors.New("launch pad is in a no-fly zone")
+
+// Inspection is a flight from the pad over a selection of trees and back.
+type Inspection struct {
+	// Trees are the trees inspected, in visiting order.
+	Trees []repository.EstateTree
+	// Skipped are the trees left out because no-fly zones cover or enclose
+	// them.
+	Skipped []repository.EstateTree
+	// Route is the waypoints from take off to landing back on the pad.
+	Route []Waypoint
+	// Distance is the total distance in meters flown.
+	Distance int
+}
+
+// stop is a point of an inspection tour the drone hovers at.
+type stop struct {
+	plot     Position
+	altitude int
+	tree     repository.EstateTree
+}
+
+// Inspect plans a flight taking off from the pad, hovering above each of the
+// trees at the clearance and landing back on the pad. The visiting order is a
+// nearest-neighbour tour improved by 2-opt. Between two trees the drone flies
+// in a straight line, climbing first above every tree the line crosses and
+// descending once above the next tree. When the line crosses a no-fly zone
+// the drone follows a chain of plots around it instead, and the tour is
+// improved on the length of that chain.
+func (p *Planner) Inspect(pad Position, trees []repository.EstateTree) (Inspection, error) {
+	var inspection Inspection
+	if len(trees) > MaxInspectionTrees {
+		return inspection, ErrTooManyTrees
+	}
+	if p.restricted(pad) {
+		return inspection, ErrPadInNoFlyZone
+	}
+
+	stops := []stop{{plot: pad, altitude: p.ground(pad)}}
+	for _, tree := range trees {
+		plot := Position{X: tree.X, Y: tree.Y}
+		if p.restricted(plot) || (plot != pad && len(p.zones) > 0 && p.detour(pad, plot) == nil) {
+			inspection.Skipped = append(inspection.Skipped, tree)
+			continue
+		}
+		stops = append(stops, stop{plot: plot, altitude: p.altitude(plot), tree: tree})
+	}
+
+	r := &route{waypoints: []Waypoint{{X: pad.X, Y: pad.Y, Altitude: p.ground(pad)}}}
+	if len(stops) > 1 {
+		costs := make([][]float64, len(stops))
+		for i := range stops {
+			costs[i] = make([]float64, len(stops))
+			for j := 0; j < i; j++ {
+				costs[i][j] = p.leg(stops[i], stops[j])
+				costs[j][i] = costs[i][j]
+			}
+		}
+
+		order := tour(costs)
+		for i, index := range order {
+			if index != 0 {
+				inspection.Trees = append(inspection.Trees, stops[index].tree)
+			}
+			p.hop(r, stops[index], stops[order[(i+1)%len(order)]])
+		}
+	}
+
+	inspection.Route = r.waypoints
+	inspection.Distance = r.waypoints[len(r.waypoints)-1].Distance
+	return inspection, nil
+}
+
+// leg returns the distance hop flies from a stop to another.
+func (p *Planner) leg(from, to stop) float64 {
+	horizontal := 0.0
+	at, altitude := from.plot, from.altitude
+	for _, next := range p.way(from.plot, to.plot) {
+		cruise, _ := p.overfly(at, next)
+		altitude = max(altitude, cruise)
+		horizontal += PlotSize * math.Hypot(float64(next.X-at.X), float64(next.Y-at.Y))
+		at = next
+	}
+	return horizontal + float64(altitude-from.altitude+altitude-to.altitude)
+}
+
+// way returns the plots the drone flies straight to one after the other from
+// a plot to another, the first plot excluded: the destination, or a chain of
+// plots around the no-fly zones the straight line crosses.
+func (p *Planner) way(from, to Position) []Position {
+	if _, clear := p.overfly(from, to); !clear {
+		return p.detour(from, to)
+	}
+	return []Position{to}
+}
+
+// hop flies the drone from a stop to the next one, never descending before it
+// is above the next stop.
+func (p *Planner) hop(r *route, from, to stop) {
+	at, altitude := from.plot, from.altitude
+	for _, next := range p.way(from.plot, to.plot) {
+		cruise, _ := p.overfly(at, next)
+		altitude = max(altitude, cruise)
+		r.fly(at, altitude)
+		r.fly(next, altitude)
+		at = next
+	}
+	r.fly(to.plot, to.altitude)
+}
+
+// overfly returns the altitude clearing every plot crossed by the straight
+// line between two plots and whether the line stays out of the no-fly zones.
+func (p *Planner) overfly(from, to Position) (altitude int, clear bool) {
+	if len(p.zones) == 0 {
+		return p.tallest(from, to), true
+	}
+
+	clear = true
+	crossing(from, to, func(plot Position) {
+		altitude = max(altitude, p.altitude(plot))
+		if p.restricted(plot) {
+			clear = false
+		}
+	})
+	return
+}
+
+// canopy holds the plots with a tree or an elevation by row and by column,
+// sorted along them.
+type canopy struct {
+	rows    map[int][]int
+	columns map[int][]int
+}
+
+// tallest returns the altitude clearing every plot crossed by the straight
+// line between two plots, like crossing visits them. Only the trees and
+// elevations of the rows, or the columns, the line goes through are looked
+// up, so long lines over a sparse estate are not followed plot by plot.
+func (p *Planner) tallest(from, to Position) int {
+	if p.canopy == nil {
+		p.canopy = &canopy{rows: make(map[int][]int), columns: make(map[int][]int)}
+		p.features(func(plot Position) {
+			p.canopy.rows[plot.Y] = append(p.canopy.rows[plot.Y], plot.X)
+			p.canopy.columns[plot.X] = append(p.canopy.columns[plot.X], plot.Y)
+		})
+		for _, trees := range p.canopy.rows {
+			slices.Sort(trees)
+		}
+		for _, trees := range p.canopy.columns {
+			slices.Sort(trees)
+		}
+	}
+
+	// Go through the axis the line moves least along, a row at a time.
+	lines, altitude := p.canopy.rows, func(x, y int) int {
+		return p.altitude(Position{X: x, Y: y})
+	}
+	x0, y0, x1, y1 := from.X, from.Y, to.X, to.Y
+	if abs(x1-x0) < abs(y1-y0) {
+		lines, altitude = p.canopy.columns, func(x, y int) int {
+			return p.altitude(Position{X: y, Y: x})
+		}
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if y1 < y0 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+
+	// The plots without a tree or an elevation are at the clearance.
+	tallest, crossed, found := math.MinInt, 0, 0
+	dx, dy := x1-x0, y1-y0
+	for y := y0; y <= y1; y++ {
+		first, last := min(x0, x1), max(x0, x1)
+		if dy > 0 {
+			// The line crosses the row between the doubled ordinates low and
+			// high, at the doubled abscissas n / dy. A plot is crossed when
+			// its edges, at 2x - 1 and 2x + 1, reach them.
+			low, high := max(2*y0, 2*y-1), min(2*y1, 2*y+1)
+			a, b := 2*x0*dy+(low-2*y0)*dx, 2*x0*dy+(high-2*y0)*dx
+			first = (min(a, b) + dy - 1) / (2 * dy)
+			last = (max(a, b) + dy) / (2 * dy)
+		}
+
+		crossed += last - first + 1
+		features := lines[y]
+		for i, _ := slices.BinarySearch(features, first); i < len(features) && features[i] <= last; i++ {
+			tallest = max(tallest, altitude(features[i], y))
+			found++
+		}
+	}
+
+	if found < crossed {
+		tallest = max(tallest, p.clearance)
+	}
+	return tallest
+}
+
+// crossing calls visit for every plot crossed by the straight line between
+// the centers of two plots, both included. A line going through the corner of
+// two plots crosses the other two plots sharing that corner as well.
+func crossing(from, to Position, visit func(plot Position)) {
+	dx, dy := abs(to.X-from.X), abs(to.Y-from.Y)
+	sx, sy := sign(to.X-from.X), sign(to.Y-from.Y)
+
+	plot := from
+	visit(plot)
+	for ix, iy := 0, 0; ix < dx || iy < dy; {
+		switch decision := (1+2*ix)*dy - (1+2*iy)*dx; {
+		case decision == 0:
+			visit(Position{X: plot.X + sx, Y: plot.Y})
+			visit(Position{X: plot.X, Y: plot.Y + sy})
+			plot.X, plot.Y = plot.X+sx, plot.Y+sy
+			ix, iy = ix+1, iy+1
+		case decision < 0:
+			plot.X += sx
+			ix++
+		default:
+			plot.Y += sy
+			iy++
+		}
+		visit(plot)
+	}
+}
+
+// tour returns a short closed tour through every stop, starting from the
+// first one, given the symmetric costs between the stops. It builds the
+// nearest-neighbour tour then reverses sections of it while that shortens it.
+func tour(costs [][]float64) []int {
+	n := len(costs)
+	order := []int{0}
+	visited := make([]bool, n)
+	visited[0] = true
+	for current := 0; len(order) < n; {
+		next := -1
+		for candidate := range costs {
+			if !visited[candidate] && (next < 0 || costs[current][candidate] < costs[current][next]) {
+				next = candidate
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+		current = next
+	}
+
+	// The tolerance keeps rounding errors from swapping equal tours forever.
+	const tolerance = 1e-9
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				a, b := order[i-1], order[i]
+				c, d := order[j], order[(j+1)%n]
+				if costs[a][c]+costs[b][d] < costs[a][b]+costs[c][d]-tolerance {
+					for left, right := i, j; left < right; left, right = left+1, right-1 {
+						order[left], order[right] = order[right], order[left]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,192 @@
+package droneplan
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	testcases := []struct {
+		name      string
+		estate    repository.Estate
+		trees     []repository.EstateTree
+		zones     []repository.NoFlyZone
+		targets   []repository.EstateTree
+		expected  []repository.EstateTree
+		skipped   []repository.EstateTree
+		waypoints int
+		distance  int
+		err       error
+	}{
+		{
+			name:   "climb above the trees between the pad and the target",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+			targets: []repository.EstateTree{
+				tree(4, 1, 10),
+			},
+			expected: []repository.EstateTree{tree(4, 1, 10)},
+			// 21 up, 30 across, 10 down to the tree and back the same way
+			waypoints: 7,
+			distance:  122,
+		},
+		{
+			name:   "2-opt shortens the nearest-neighbour tour",
+			estate: repository.Estate{Length: 5, Width: 5},
+			targets: []repository.EstateTree{
+				tree(3, 5, 5),
+				tree(2, 1, 5),
+				tree(1, 5, 5),
+				tree(5, 5, 5),
+			},
+			expected: []repository.EstateTree{
+				tree(2, 1, 5),
+				tree(5, 5, 5),
+				tree(3, 5, 5),
+				tree(1, 5, 5),
+			},
+			waypoints: 7,
+			distance:  152,
+		},
+		{
+			name:   "detour around a zone and skip the trees inside",
+			estate: repository.Estate{Length: 5, Width: 5},
+			zones:  []repository.NoFlyZone{rectangle(3, 1, 3, 4)},
+			targets: []repository.EstateTree{
+				tree(5, 1, 0),
+				tree(3, 2, 0),
+			},
+			expected: []repository.EstateTree{tree(5, 1, 0)},
+			skipped:  []repository.EstateTree{tree(3, 2, 0)},
+			// 12 plots through the gap at (3, 5) each way
+			waypoints: 11,
+			distance:  242,
+		},
+		{
+			name:   "tour costs the detours around a zone",
+			estate: repository.Estate{Length: 5, Width: 5},
+			zones:  []repository.NoFlyZone{rectangle(1, 3, 3, 3)},
+			targets: []repository.EstateTree{
+				tree(5, 1, 0),
+				tree(5, 3, 0),
+				tree(3, 5, 0),
+				tree(4, 4, 0),
+			},
+			// Costed in straight lines across the zone the tour flies 170
+			expected: []repository.EstateTree{
+				tree(5, 1, 0),
+				tree(3, 5, 0),
+				tree(4, 4, 0),
+				tree(5, 3, 0),
+			},
+			waypoints: 7,
+			distance:  160,
+		},
+		{
+			name:    "pad in a zone",
+			estate:  repository.Estate{Length: 5, Width: 5},
+			zones:   []repository.NoFlyZone{rectangle(1, 1, 1, 1)},
+			targets: []repository.EstateTree{tree(5, 1, 0)},
+			err:     ErrPadInNoFlyZone,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: tc.estate,
+				Trees:  append(tc.trees, tc.targets...),
+				Zones:  tc.zones,
+			})
+
+			inspection, err := planner.Inspect(Position{X: 1, Y: 1}, tc.targets)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, inspection.Trees)
+			require.Equal(t, tc.skipped, inspection.Skipped)
+			require.Len(t, inspection.Route, tc.waypoints)
+			require.Equal(t, tc.distance, inspection.Distance)
+
+			for _, waypoint := range inspection.Route {
+				require.False(t, planner.restricted(Position{X: waypoint.X, Y: waypoint.Y}))
+			}
+		})
+	}
+}
+
+func TestCrossing(t *testing.T) {
+	testcases := []struct {
+		name     string
+		from, to Position
+		expected []Position
+	}{
+		{
+			name:     "same plot",
+			from:     Position{X: 2, Y: 2},
+			to:       Position{X: 2, Y: 2},
+			expected: []Position{{X: 2, Y: 2}},
+		},
+		{
+			name:     "along a line",
+			from:     Position{X: 3, Y: 1},
+			to:       Position{X: 1, Y: 1},
+			expected: []Position{{X: 3, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 1}},
+		},
+		{
+			name: "through corners on the diagonal",
+			from: Position{X: 1, Y: 1},
+			to:   Position{X: 2, Y: 2},
+			expected: []Position{
+				{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2},
+			},
+		},
+		{
+			name: "steep line",
+			from: Position{X: 1, Y: 1},
+			to:   Position{X: 2, Y: 3},
+			expected: []Position{
+				{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}, {X: 2, Y: 3},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var plots []Position
+			crossing(tc.from, tc.to, func(plot Position) {
+				plots = append(plots, plot)
+			})
+			require.Equal(t, tc.expected, plots)
+		})
+	}
+}
+
+// TestTallest checks the trees found along a line against the plots visited
+// by crossing.
+func TestTallest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		estate := repository.Estate{Length: random.Intn(12) + 1, Width: random.Intn(12) + 1}
+		planner := NewPlanner(NewPlannerOptions{
+			Estate:     estate,
+			Trees:      randomTrees(random, estate, random.Intn(estate.Length*estate.Width+1)),
+			Elevations: randomElevations(random, estate, random.Intn(2)*random.Intn(estate.Length*estate.Width+1)),
+		})
+
+		from := Position{X: random.Intn(estate.Length) + 1, Y: random.Intn(estate.Width) + 1}
+		to := Position{X: random.Intn(estate.Length) + 1, Y: random.Intn(estate.Width) + 1}
+		expected := math.MinInt
+		crossing(from, to, func(plot Position) {
+			expected = max(expected, planner.altitude(plot))
+		})
+		require.Equal(t, expected, planner.tallest(from, to))
+	}
+}
@@ -0,0 +1,177 @@
+package droneplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// MAVLink commands and frames used in mission files.
+const (
+	CommandWaypoint        = 16
+	CommandReturnToLaunch  = 20
+	CommandTakeoff         = 22
+	FrameGlobal            = 0
+	FrameGlobalRelativeAlt = 3
+)
+
+const earthRadius = 6378137.0
+
+// Origin is the geographic location of the center of plot (1, 1). The x axis
+// of the estate points east and the y axis points north.
+type Origin struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Locate returns the latitude and longitude of the center of a plot.
+func (o Origin) Locate(x, y int) (latitude, longitude float64) {
+	north := float64((y - 1) * PlotSize)
+	east := float64((x - 1) * PlotSize)
+
+	latitude = o.Latitude + north/earthRadius*180/math.Pi
+	longitude = o.Longitude + east/(earthRadius*math.Cos(o.Latitude*math.Pi/180))*180/math.Pi
+	return
+}
+
+// MissionItem is a command of a mission flown by an autopilot. The altitude
+// is in meters relative to the take off plot.
+type MissionItem struct {
+	Command   int
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+}
+
+// Mission converts a route into the mission items an autopilot flies: a take
+// off above the start plot, a waypoint for every point of the route between
+// the take off and the landing and a return to launch. The route starts on
+// the ground of the take off plot and its altitudes above the datum of the
+// terrain are made relative to that ground. An empty route has no items.
+func Mission(route []Waypoint, origin Origin) []MissionItem {
+	if len(route) == 0 {
+		return nil
+	}
+
+	items := make([]MissionItem, 0, len(route))
+
+	ground := route[0].Altitude
+	for i := 1; i < len(route)-1; i++ {
+		command := CommandWaypoint
+		if i == 1 {
+			command = CommandTakeoff
+		}
+
+		latitude, longitude := origin.Locate(route[i].X, route[i].Y)
+		items = append(items, MissionItem{
+			Command:   command,
+			Latitude:  latitude,
+			Longitude: longitude,
+			Altitude:  float64(route[i].Altitude - ground),
+		})
+	}
+
+	return append(items, MissionItem{Command: CommandReturnToLaunch})
+}
+
+type qgcPlan struct {
+	FileType      string         `json:"fileType"`
+	Version       int            `json:"version"`
+	GroundStation string         `json:"groundStation"`
+	Mission       qgcMission     `json:"mission"`
+	GeoFence      qgcGeoFence    `json:"geoFence"`
+	RallyPoints   qgcRallyPoints `json:"rallyPoints"`
+}
+
+type qgcMission struct {
+	Version             int              `json:"version"`
+	FirmwareType        int              `json:"firmwareType"`
+	VehicleType         int              `json:"vehicleType"`
+	CruiseSpeed         float64          `json:"cruiseSpeed"`
+	HoverSpeed          float64          `json:"hoverSpeed"`
+	PlannedHomePosition [3]float64       `json:"plannedHomePosition"`
+	Items               []qgcMissionItem `json:"items"`
+}
+
+type qgcMissionItem struct {
+	Type         string     `json:"type"`
+	AutoContinue bool       `json:"autoContinue"`
+	Command      int        `json:"command"`
+	DoJumpId     int        `json:"doJumpId"`
+	Frame        int        `json:"frame"`
+	Params       [7]float64 `json:"params"`
+	Altitude     float64    `json:"Altitude"`
+	AltitudeMode int        `json:"AltitudeMode"`
+}
+
+type qgcGeoFence struct {
+	Version  int   `json:"version"`
+	Circles  []any `json:"circles"`
+	Polygons []any `json:"polygons"`
+}
+
+type qgcRallyPoints struct {
+	Version int   `json:"version"`
+	Points  []any `json:"points"`
+}
+
+// WriteQGCPlan writes the mission as a QGroundControl .plan file for an
+// ArduPilot multirotor, planned from home at the take off location.
+func WriteQGCPlan(w io.Writer, items []MissionItem) error {
+	plan := qgcPlan{
+		FileType:      "Plan",
+		Version:       1,
+		GroundStation: "QGroundControl",
+		Mission: qgcMission{
+			Version:             2,
+			FirmwareType:        3,
+			VehicleType:         2,
+			CruiseSpeed:         15,
+			HoverSpeed:          5,
+			PlannedHomePosition: [3]float64{items[0].Latitude, items[0].Longitude, 0},
+			Items:               make([]qgcMissionItem, 0, len(items)),
+		},
+		GeoFence:    qgcGeoFence{Version: 2, Circles: []any{}, Polygons: []any{}},
+		RallyPoints: qgcRallyPoints{Version: 2, Points: []any{}},
+	}
+
+	for i, item := range items {
+		plan.Mission.Items = append(plan.Mission.Items, qgcMissionItem{
+			Type:         "SimpleItem",
+			AutoContinue: true,
+			Command:      item.Command,
+			DoJumpId:     i + 1,
+			Frame:        FrameGlobalRelativeAlt,
+			Params:       [7]float64{0, 0, 0, 0, item.Latitude, item.Longitude, item.Altitude},
+			Altitude:     item.Altitude,
+			AltitudeMode: 1,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(plan)
+}
+
+// WriteMavlinkWPL writes the mission as a MAVLink waypoint list (QGC WPL 110),
+// the first line being the home position at the take off location.
+func WriteMavlinkWPL(w io.Writer, items []MissionItem) error {
+	if _, err := fmt.Fprintln(w, "QGC WPL 110"); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "0\t1\t%d\t%d\t0\t0\t0\t0\t%.8f\t%.8f\t0\t1\n", FrameGlobal, CommandWaypoint, items[0].Latitude, items[0].Longitude)
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		_, err = fmt.Fprintf(w, "%d\t0\t%d\t%d\t0\t0\t0\t0\t%.8f\t%.8f\t%.2f\t1\n", i+1, FrameGlobalRelativeAlt, item.Command, item.Latitude, item.Longitude, item.Altitude)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
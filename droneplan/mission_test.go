@@ -0,0 +1,106 @@
+package droneplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginLocate(t *testing.T) {
+	origin := Origin{Latitude: 1.5, Longitude: 101.5}
+
+	latitude, longitude := origin.Locate(1, 1)
+	require.Equal(t, origin.Latitude, latitude)
+	require.Equal(t, origin.Longitude, longitude)
+
+	latitude, longitude = origin.Locate(1, 2)
+	require.InDelta(t, 1.5+0.0000898, latitude, 0.0000001)
+	require.Equal(t, origin.Longitude, longitude)
+
+	latitude, longitude = origin.Locate(2, 1)
+	require.Equal(t, origin.Latitude, latitude)
+	require.InDelta(t, 101.5+0.0000899, longitude, 0.0000001)
+}
+
+func TestMission(t *testing.T) {
+	estate := repository.Estate{Length: 5, Width: 1}
+	trees := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+	origin := Origin{Latitude: 1.5, Longitude: 101.5}
+
+	planner := NewPlanner(NewPlannerOptions{Estate: estate, Trees: trees})
+	items := Mission(planner.Route(), origin)
+
+	commands := make([]int, 0, len(items))
+	altitudes := make([]float64, 0, len(items))
+	for _, item := range items {
+		commands = append(commands, item.Command)
+		altitudes = append(altitudes, item.Altitude)
+	}
+	require.Equal(t, []int{
+		CommandTakeoff,
+		CommandWaypoint,
+		CommandWaypoint,
+		CommandWaypoint,
+		CommandWaypoint,
+		CommandWaypoint,
+		CommandReturnToLaunch,
+	}, commands)
+	require.Equal(t, []float64{11, 11, 21, 21, 11, 11, 0}, altitudes)
+
+	t.Run("qgc plan", func(t *testing.T) {
+		var body bytes.Buffer
+		require.NoError(t, WriteQGCPlan(&body, items))
+
+		var plan qgcPlan
+		require.NoError(t, json.Unmarshal(body.Bytes(), &plan))
+		require.Equal(t, "Plan", plan.FileType)
+		require.Len(t, plan.Mission.Items, len(items))
+		require.Equal(t, CommandTakeoff, plan.Mission.Items[0].Command)
+		require.Equal(t, 11.0, plan.Mission.Items[0].Params[6])
+	})
+
+	t.Run("mavlink waypoint list", func(t *testing.T) {
+		var body bytes.Buffer
+		require.NoError(t, WriteMavlinkWPL(&body, items))
+
+		lines := strings.Split(strings.TrimSpace(body.String()), "\n")
+		require.Len(t, lines, len(items)+2)
+		require.Equal(t, "QGC WPL 110", lines[0])
+		require.Equal(t, "0\t1\t0\t16\t0\t0\t0\t0\t1.50000000\t101.50000000\t0\t1", lines[1])
+		require.Equal(t, "1\t0\t3\t22\t0\t0\t0\t0\t1.50000000\t101.50000000\t11.00\t1", lines[2])
+	})
+
+	t.Run("raised terrain", func(t *testing.T) {
+		planner := NewPlanner(NewPlannerOptions{
+			Estate: repository.Estate{Length: 3, Width: 1},
+			Elevations: []repository.PlotElevation{
+				{X: 1, Y: 1, Elevation: 5},
+				{X: 2, Y: 1, Elevation: 15},
+				{X: 3, Y: 1, Elevation: 5},
+			},
+		})
+		items := Mission(planner.Route(), origin)
+
+		commands := make([]int, 0, len(items))
+		altitudes := make([]float64, 0, len(items))
+		for _, item := range items {
+			commands = append(commands, item.Command)
+			altitudes = append(altitudes, item.Altitude)
+		}
+		// The drone crosses the hill 11 meters above the take off plot.
+		require.Equal(t, []int{
+			CommandTakeoff,
+			CommandWaypoint,
+			CommandReturnToLaunch,
+		}, commands)
+		require.Equal(t, []float64{11, 11, 0}, altitudes)
+	})
+}
@@ -0,0 +1,432 @@
+package droneplan
+
+import (
+	"slices"
+	"sort"
+)
+
+// level is a plot of the course, by its position in it, and the altitude the
+// drone crosses it at.
+type level struct {
+	index    int
+	altitude int
+}
+
+// piece is a stretch of consecutive plots of the sweep, or a detour between
+// two stretches around the no-fly zones.
+type piece struct {
+	// offset is the number of plots flown over before the piece.
+	offset int
+	// index is the index in the sweep of the first plot of a stretch, or of
+	// the first plot of the stretch after a detour.
+	index int
+	plots int
+	// detour are the plots flown over by a detour, nil for a stretch.
+	detour []Position
+}
+
+// course is the plots the sweep flies over, in flight order: the stretches of
+// the sweep outside the no-fly zones joined by detours around them. A detour
+// ends next to the first plot of the stretch after it.
+type course struct {
+	pieces []piece
+	// plots is the number of plots flown over.
+	plots int
+	// start is the plot the drone takes off from, the zero Position when the
+	// zones cover the whole estate.
+	start Position
+	// skipped are the first and last indexes of the runs of plots of the
+	// sweep the zones cover or enclose, in order.
+	skipped [][2]int
+}
+
+// sweepCourse returns the plots the sweep flies over. Without no-fly zones it
+// is the whole sweep, with zones it is found in time proportional to the
+// lines of the sweep and the plots of the detours.
+func (p *Planner) sweepCourse() *course {
+	if p.course != nil {
+		return p.course
+	}
+
+	lines, plots := p.lines()
+	total := lines * plots
+	c := &course{}
+	p.course = c
+	if len(p.zones) == 0 {
+		c.pieces = []piece{{plots: total}}
+		c.plots = total
+		c.start = p.position(0)
+		return c
+	}
+
+	// The drone takes off from the first plot outside the zones and surveys
+	// the plots it can reach from there.
+	o := p.zoneObstacles()
+	first, next := -1, 0
+	p.skips(o, func(component int) bool {
+		return component < 0
+	}, func(from, to int) bool {
+		if from > next {
+			first = next
+			return false
+		}
+		next = to + 1
+		return true
+	})
+	if first < 0 && next < total {
+		first = next
+	}
+	if first < 0 {
+		c.skipped = [][2]int{{0, total - 1}}
+		return c
+	}
+
+	c.start = p.position(first)
+	start := o.component(p.line(c.start), p.step(c.start))
+	p.skips(o, func(component int) bool {
+		return component != start
+	}, func(from, to int) bool {
+		c.skipped = append(c.skipped, [2]int{from, to})
+		return true
+	})
+
+	var last Position
+	stretch := func(from, to int) {
+		if from > to {
+			return
+		}
+		if len(c.pieces) > 0 {
+			if next := p.position(from); manhattan(last, next) > 1 {
+				path := p.detour(last, next)
+				c.pieces = append(c.pieces, piece{offset: c.plots, index: from, plots: len(path) - 1, detour: path[:len(path)-1]})
+				c.plots += len(path) - 1
+			}
+		}
+		c.pieces = append(c.pieces, piece{offset: c.plots, index: from, plots: to - from + 1})
+		c.plots += to - from + 1
+		last = p.position(to)
+	}
+	from := 0
+	for _, run := range c.skipped {
+		stretch(from, run[0]-1)
+		from = run[1] + 1
+	}
+	stretch(from, total-1)
+
+	return c
+}
+
+// skips calls visit for the runs of plots of the sweep whose component is
+// bad, by first and last index, in order, until visit returns false.
+func (p *Planner) skips(o *obstacles, bad func(component int) bool, visit func(first, last int) bool) {
+	pending, stopped := [2]int{-1, -1}, false
+	mark := func(first, last int) {
+		switch {
+		case stopped:
+		case pending[0] >= 0 && pending[1] == first-1:
+			pending[1] = last
+		case pending[0] >= 0 && !visit(pending[0], pending[1]):
+			stopped = true
+		default:
+			pending = [2]int{first, last}
+		}
+	}
+
+	p.runs(func(index, line, step, lineMove, stepMove, plots int) bool {
+		var found [][2]int
+		collect := func(first, last int) {
+			found = append(found, [2]int{first, last})
+		}
+
+		origin, move := step, stepMove
+		if lineMove == 0 {
+			o.along(line, min(step, step+stepMove*(plots-1)), max(step, step+stepMove*(plots-1)), bad, collect)
+		} else {
+			origin, move = line, lineMove
+			o.across(step, min(line, line+lineMove*(plots-1)), max(line, line+lineMove*(plots-1)), bad, collect)
+		}
+		if move < 0 {
+			slices.Reverse(found)
+		}
+		for _, run := range found {
+			first, last := index+(run[0]-origin)*move, index+(run[1]-origin)*move
+			mark(min(first, last), max(first, last))
+		}
+		return !stopped
+	})
+	if !stopped && pending[0] >= 0 {
+		visit(pending[0], pending[1])
+	}
+}
+
+// runs calls visit for every straight run of plots of the sweep, in flight
+// order, with the index of its first plot, the line and step of that plot,
+// the moves in lines and steps to the next plot and its number of plots,
+// until visit returns false.
+func (p *Planner) runs(visit func(index, line, step, lineMove, stepMove, plots int) bool) {
+	lines, plots := p.lines()
+	if p.strategy != Spiral {
+		for line := 0; line < lines; line++ {
+			step, move := 0, 1
+			if line%2 == 1 {
+				step, move = plots-1, -1
+			}
+			if !visit(line*plots, line, step, 0, move, plots) {
+				return
+			}
+		}
+		return
+	}
+
+	// The sides of every ring, like spiralPosition.
+	for k := 0; k < (min(lines, plots)+1)/2; k++ {
+		h, w := lines-2*k, plots-2*k
+		index := lines*plots - h*w
+		end := index + h*w - max(h-2, 0)*max(w-2, 0)
+		for _, side := range [][5]int{
+			{k, k, 0, 1, w},
+			{k + 1, k + w - 1, 1, 0, h - 1},
+			{k + h - 1, k + w - 2, 0, -1, w - 1},
+			{k + h - 2, k, -1, 0, h - 2},
+		} {
+			n := min(side[4], end-index)
+			if n <= 0 {
+				continue
+			}
+			if !visit(index, side[0], side[1], side[2], side[3], n) {
+				return
+			}
+			index += n
+		}
+	}
+}
+
+// piece returns the index of the piece holding the position in the course.
+func (c *course) piece(position int) int {
+	return sort.Search(len(c.pieces), func(i int) bool {
+		return c.pieces[i].offset+c.pieces[i].plots > position
+	})
+}
+
+// straight returns the furthest position of the course the drone reaches
+// from the position along the sweep, the next position out of a stretch.
+func (c *course) straight(position int) int {
+	piece := c.pieces[c.piece(position)]
+	if piece.detour == nil && position < piece.offset+piece.plots-1 {
+		return piece.offset + piece.plots - 1
+	}
+	return position + 1
+}
+
+// flown returns the plot at the position in the course.
+func (p *Planner) flown(position int) Position {
+	c := p.sweepCourse()
+	piece := c.pieces[c.piece(position)]
+	if piece.detour != nil {
+		return piece.detour[position-piece.offset]
+	}
+	return p.position(piece.index + position - piece.offset)
+}
+
+// relief returns the plots of the course with a tree or an elevation, by
+// position in the course, in order.
+func (p *Planner) relief(c *course) []level {
+	levels := make([]level, 0, len(p.heights)+len(p.elevations))
+	p.features(func(plot Position) {
+		index := p.index(plot)
+		i := sort.Search(len(c.pieces), func(i int) bool {
+			if c.pieces[i].detour != nil {
+				return c.pieces[i].index > index
+			}
+			return c.pieces[i].index+c.pieces[i].plots > index
+		})
+		if i < len(c.pieces) && c.pieces[i].detour == nil && c.pieces[i].index <= index {
+			levels = append(levels, level{index: c.pieces[i].offset + index - c.pieces[i].index, altitude: p.altitude(plot)})
+		}
+	})
+
+	// The detours may fly over the trees and elevations again.
+	for _, piece := range c.pieces {
+		for i, plot := range piece.detour {
+			_, tree := p.heights[plot]
+			_, elevation := p.elevations[plot]
+			if tree || elevation {
+				levels = append(levels, level{index: piece.offset + i, altitude: p.altitude(plot)})
+			}
+		}
+	}
+
+	slices.SortFunc(levels, func(a, b level) int {
+		return a.index - b.index
+	})
+	return levels
+}
+
+// glide calls move for every stretch of the flight, in flight order, from a
+// plot to the plot steps further in the course. A stretch longer than one
+// step is flown level at the clearance along the sweep, possibly across
+// several lines, so an estate is flown in time proportional to its trees and
+// the lines crossing no-fly zones instead of its plots. The detours around
+// the zones are flown plot by plot. It stops as soon as move returns false
+// and reports whether the whole estate was swept.
+func (p *Planner) glide(move func(from, to hover, steps int) bool) bool {
+	c := p.sweepCourse()
+	if c.plots == 0 {
+		return true
+	}
+
+	hover := func(l level) hover {
+		return hover{plot: p.flown(l.index), altitude: l.altitude}
+	}
+	hop := func(from, to level) bool {
+		for from.index < to.index {
+			next := level{index: min(c.straight(from.index), to.index), altitude: from.altitude}
+			if next.index == to.index {
+				next.altitude = to.altitude
+			}
+			if !move(hover(from), hover(next), next.index-from.index) {
+				return false
+			}
+			from = next
+		}
+		return true
+	}
+
+	levels := p.profile(p.relief(c), c.plots-1)
+	for i := 1; i < len(levels); i++ {
+		from, to := levels[i-1], levels[i]
+		if to.index == from.index+1 {
+			if !hop(from, to) {
+				return false
+			}
+			continue
+		}
+
+		// Down to the clearance past the first plot, level over the empty
+		// plots and up to the next one.
+		down := level{index: from.index + 1, altitude: p.clearance}
+		up := level{index: to.index - 1, altitude: p.clearance}
+		if !hop(from, down) || !hop(down, up) || !hop(up, to) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// profile returns the positions of the course up to last the drone does not
+// cross at the clearance, in flight order, along with the first and last
+// positions, from the trees and elevations of the course in order. Only the
+// positions within the look-ahead window of a tree can be above the
+// clearance, so every run of them is smoothed on its own.
+func (p *Planner) profile(trees []level, last int) []level {
+	levels := make([]level, 0, len(trees)+2)
+	if len(trees) == 0 || trees[0].index-p.lookAhead > 0 {
+		levels = append(levels, level{index: 0, altitude: p.clearance})
+	}
+
+	// The buffers are reused by every run.
+	var run, behind, ahead, queue []int
+	for first := 0; first < len(trees); {
+		// The run spans the windows of consecutive trees that overlap.
+		end := first + 1
+		for end < len(trees) && trees[end].index-p.lookAhead <= trees[end-1].index+p.lookAhead+1 {
+			end++
+		}
+
+		offset := max(trees[first].index-p.lookAhead, 0)
+		size := min(trees[end-1].index+p.lookAhead, last) - offset + 1
+		run, behind, ahead = resize(run, size), resize(behind, size), resize(ahead, size)
+		for i := range run {
+			run[i] = p.clearance
+		}
+		for _, tree := range trees[first:end] {
+			run[tree.index-offset] = tree.altitude
+		}
+
+		queue = slide(behind, run, queue, p.lookAhead, 1)
+		queue = slide(ahead, run, queue, p.lookAhead, -1)
+		for i := range run {
+			altitude := min(behind[i], ahead[i])
+			if altitude != p.clearance || offset+i == 0 || offset+i == last {
+				levels = append(levels, level{index: offset + i, altitude: altitude})
+			}
+		}
+
+		first = end
+	}
+
+	if levels[len(levels)-1].index != last {
+		levels = append(levels, level{index: last, altitude: p.clearance})
+	}
+	return levels
+}
+
+// slide sets every highest to the highest of the altitudes from the same
+// index to size indexes before it, or after it when step is -1. The
+// candidates for the highest altitude are kept in the queue by decreasing
+// altitude, the queue is returned for reuse.
+func slide(highest, altitudes, queue []int, size, step int) []int {
+	i := 0
+	if step < 0 {
+		i = len(altitudes) - 1
+	}
+
+	queue, head := queue[:0], 0
+	for ; i >= 0 && i < len(altitudes); i += step {
+		for len(queue) > head && altitudes[queue[len(queue)-1]] <= altitudes[i] {
+			queue = queue[:len(queue)-1]
+		}
+		queue = append(queue, i)
+		if abs(i-queue[head]) > size {
+			head++
+		}
+		highest[i] = altitudes[queue[head]]
+	}
+	return queue
+}
+
+// resize returns the buffer with the given length, growing it when needed.
+func resize(buffer []int, length int) []int {
+	return slices.Grow(buffer[:0], length)[:length]
+}
+
+// index returns the number of plots before the plot in the sweep.
+func (p *Planner) index(plot Position) int {
+	if p.strategy == Spiral {
+		return p.spiralIndex(plot)
+	}
+	return p.zigzagIndex(plot)
+}
+
+// zigzagIndex returns the number of plots before the plot in the zigzag.
+func (p *Planner) zigzagIndex(plot Position) int {
+	_, plots := p.lines()
+
+	step, line := p.step(plot), p.line(plot)
+	if line%2 == 1 {
+		step = plots - 1 - step
+	}
+	return line*plots + step
+}
+
+// position returns the plot at the index of the sweep.
+func (p *Planner) position(index int) Position {
+	if p.strategy == Spiral {
+		return p.spiralPosition(index)
+	}
+
+	_, plots := p.lines()
+	return p.at(index/plots, index%plots)
+}
+
+// turn returns the index of the plot of the sweep where the drone turns next,
+// the last plot of the straight run the plot at the index is on.
+func (p *Planner) turn(index int) int {
+	if p.strategy == Spiral {
+		return p.spiralTurn(index)
+	}
+
+	_, plots := p.lines()
+	return (index/plots+1)*plots - 1
+}
@@ -0,0 +1,356 @@
+package droneplan
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+// randomTrees returns count trees on distinct plots of the estate.
+func randomTrees(random *rand.Rand, estate repository.Estate, count int) []repository.EstateTree {
+	taken := make(map[Position]bool, count)
+	trees := make([]repository.EstateTree, 0, count)
+	for len(trees) < count {
+		plot := Position{X: random.Intn(estate.Length) + 1, Y: random.Intn(estate.Width) + 1}
+		if taken[plot] {
+			continue
+		}
+		taken[plot] = true
+		trees = append(trees, tree(plot.X, plot.Y, random.Intn(30)+1))
+	}
+	return trees
+}
+
+// randomElevations returns the elevations of count distinct plots of the
+// estate, some of them below the datum.
+func randomElevations(random *rand.Rand, estate repository.Estate, count int) []repository.PlotElevation {
+	elevations := make([]repository.PlotElevation, 0, count)
+	for _, tree := range randomTrees(random, estate, count) {
+		elevations = append(elevations, repository.PlotElevation{X: tree.X, Y: tree.Y, Elevation: float64(tree.Height - 10)})
+	}
+	return elevations
+}
+
+// walk calls move for every step of the sweep from one plot to the next, in
+// flight order, plot by plot, the reference glide is checked against. It
+// stops as soon as move returns false and reports whether the whole estate
+// was swept.
+//
+// With a look-ahead window every plot is crossed at the lower of the highest
+// altitudes within the window behind and ahead of it, so the drone holds its
+// altitude across dips no longer than the window. The first and last plots
+// are never raised.
+func (p *Planner) walk(move func(from, to hover) bool) bool {
+	if p.lookAhead == 0 {
+		return p.traverse(func(from, to Position) bool {
+			return move(hover{plot: from, altitude: p.altitude(from)}, hover{plot: to, altitude: p.altitude(to)})
+		}, func(Position) {})
+	}
+
+	// window holds the plots of the flight from index offset on, with the
+	// altitudes following the canopy, next is the index of the next plot to
+	// move to.
+	start := p.first()
+	window := []hover{{plot: start, altitude: p.altitude(start)}}
+	offset, next := 0, 0
+	var previous hover
+	smooth := func() bool {
+		current := window[next-offset]
+		behind, ahead := current.altitude, current.altitude
+		for i := max(offset, next-p.lookAhead); i < next; i++ {
+			behind = max(behind, window[i-offset].altitude)
+		}
+		for i := next + 1; i <= next+p.lookAhead && i < offset+len(window); i++ {
+			ahead = max(ahead, window[i-offset].altitude)
+		}
+		current.altitude = min(behind, ahead)
+
+		if next > 0 && !move(previous, current) {
+			return false
+		}
+		previous = current
+		next++
+		if next-offset > p.lookAhead {
+			window = window[1:]
+			offset++
+		}
+		return true
+	}
+
+	completed := p.traverse(func(from, to Position) bool {
+		window = append(window, hover{plot: to, altitude: p.altitude(to)})
+		if offset+len(window)-next > p.lookAhead {
+			return smooth()
+		}
+		return true
+	}, func(Position) {})
+	if !completed {
+		return false
+	}
+
+	for next < offset+len(window) {
+		if !smooth() {
+			return false
+		}
+	}
+	return true
+}
+
+// traverse calls move for every step from one plot to the next, in flight
+// order, and skip for every plot the drone does not survey. Plots of the
+// zigzag that are not next to each other are joined by a detour around the
+// no-fly zones. It stops as soon as move returns false and reports whether
+// the whole estate was swept.
+func (p *Planner) traverse(move func(from, to Position) bool, skip func(plot Position)) bool {
+	lines, plots := p.lines()
+
+	from := p.first()
+	for index := 0; index < lines*plots; index++ {
+		to := p.position(index)
+		if to == from {
+			continue
+		}
+		if p.restricted(to) {
+			skip(to)
+			continue
+		}
+
+		path := []Position{to}
+		if manhattan(from, to) > 1 {
+			path = p.detour(from, to)
+		}
+		if path == nil {
+			skip(to)
+			continue
+		}
+
+		for _, next := range path {
+			if !move(from, next) {
+				return false
+			}
+			from = next
+		}
+	}
+
+	return true
+}
+
+// first returns the first plot of the sweep outside the no-fly zones, plot by
+// plot, the zero Position when the zones cover the whole estate.
+func (p *Planner) first() Position {
+	lines, plots := p.lines()
+	for index := 0; index < lines*plots; index++ {
+		if plot := p.position(index); !p.restricted(plot) {
+			return plot
+		}
+	}
+
+	return Position{}
+}
+
+// expand returns the plots of the runs, in order.
+func expand(runs []Run) []Position {
+	var plots []Position
+	for _, run := range runs {
+		move := Position{X: sign(run.End.X - run.Start.X), Y: sign(run.End.Y - run.Start.Y)}
+		for plot := run.Start; ; plot = (Position{X: plot.X + move.X, Y: plot.Y + move.Y}) {
+			plots = append(plots, plot)
+			if plot == run.End {
+				break
+			}
+		}
+	}
+	return plots
+}
+
+// randomZones returns count no-fly zones over the estate, rectangles and
+// triangles whose vertices are on or halfway between the plot centers.
+func randomZones(random *rand.Rand, estate repository.Estate, count int) []repository.NoFlyZone {
+	point := func() repository.Point {
+		return repository.Point{X: float64(random.Intn(2*estate.Length+2)) / 2, Y: float64(random.Intn(2*estate.Width+2)) / 2}
+	}
+
+	zones := make([]repository.NoFlyZone, 0, count)
+	for len(zones) < count {
+		if random.Intn(2) == 0 {
+			a, b := point(), point()
+			zones = append(zones, rectangle(min(a.X, b.X), min(a.Y, b.Y), max(a.X, b.X), max(a.Y, b.Y)))
+			continue
+		}
+		zones = append(zones, repository.NoFlyZone{Vertices: []repository.Point{point(), point(), point()}})
+	}
+	return zones
+}
+
+// TestGlide checks the flight over the profile of the estate against the
+// flight plot by plot, for both sweeps of the plots, over flat and hilly
+// estates, with and without no-fly zones.
+func TestGlide(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		estate := repository.Estate{Length: random.Intn(8) + 1, Width: random.Intn(8) + 1}
+		planner := NewPlanner(NewPlannerOptions{
+			Estate:     estate,
+			Trees:      randomTrees(random, estate, random.Intn(estate.Length*estate.Width/2+1)),
+			Strategy:   []Strategy{Zigzag, Spiral}[random.Intn(2)],
+			Elevations: randomElevations(random, estate, random.Intn(2)*random.Intn(estate.Length*estate.Width+1)),
+			Sweep:      Sweep{Corner: Corners[random.Intn(len(Corners))], Orientation: Orientations[random.Intn(len(Orientations))]},
+			Zones:      randomZones(random, estate, random.Intn(2)*random.Intn(4)),
+			Clearance:  random.Intn(3) + 1,
+			LookAhead:  random.Intn(4),
+		})
+
+		// Plot by plot, skipping the plots the zones cover or enclose.
+		start := planner.first()
+		require.Equal(t, start, planner.start())
+		var skipped []Position
+		planner.traverse(func(from, to Position) bool {
+			return true
+		}, func(plot Position) {
+			skipped = append(skipped, plot)
+		})
+		require.Equal(t, skipped, expand(planner.Skipped()))
+		if start == (Position{}) {
+			// The zones cover the whole estate, the drone does not take off.
+			require.Equal(t, Plan{}, planner.Compute(0))
+			require.Empty(t, planner.Route())
+			continue
+		}
+
+		distances := []int{planner.altitude(start) - planner.ground(start)}
+		rests := []Position{start}
+		r := &route{waypoints: []Waypoint{{X: start.X, Y: start.Y, Altitude: planner.ground(start)}}}
+		r.fly(start, planner.altitude(start))
+		landing := planner.altitude(start) - planner.ground(start)
+		planner.walk(func(from, to hover) bool {
+			distances = append(distances, distances[len(distances)-1]+PlotSize+abs(to.altitude-from.altitude))
+			rests = append(rests, to.plot)
+			landing = to.altitude - planner.ground(to.plot)
+			if to.altitude > from.altitude {
+				r.fly(from.plot, to.altitude)
+				r.fly(to.plot, to.altitude)
+			} else {
+				r.fly(to.plot, from.altitude)
+				r.fly(to.plot, to.altitude)
+			}
+			return true
+		})
+		last := r.waypoints[len(r.waypoints)-1]
+		r.fly(Position{X: last.X, Y: last.Y}, planner.ground(Position{X: last.X, Y: last.Y}))
+
+		distance := distances[len(distances)-1] + landing
+		require.Equal(t, Plan{Distance: distance, Rest: rests[len(rests)-1]}, planner.Compute(0))
+		require.Equal(t, r.waypoints, planner.Route())
+
+		if planner.strategy == Zigzag {
+			costs := 0
+			for _, cost := range planner.lineCosts() {
+				costs += cost
+			}
+			require.Equal(t, distance-landing, costs)
+		}
+
+		maxDistance := random.Intn(distance) + 1
+		reached := 0
+		for reached+1 < len(distances) && distances[reached+1] <= maxDistance {
+			reached++
+		}
+		if distances[0] > maxDistance {
+			require.Equal(t, Plan{Rest: start}, planner.Compute(maxDistance))
+		} else if maxDistance < distance {
+			require.Equal(t, Plan{Distance: distances[reached], Rest: rests[reached]}, planner.Compute(maxDistance))
+		}
+	}
+}
+
+// benchmarkPlanner returns the planner of a maximum size estate with 100k
+// trees and the no-fly zones.
+func benchmarkPlanner(lookAhead int, zones ...repository.NoFlyZone) *Planner {
+	estate := repository.Estate{Length: 50000, Width: 50000}
+	return NewPlanner(NewPlannerOptions{
+		Estate:    estate,
+		Trees:     randomTrees(rand.New(rand.NewSource(1)), estate, 100000),
+		Zones:     zones,
+		LookAhead: lookAhead,
+	})
+}
+
+// benchmarkZones are villages, a mill and a power line over the maximum size
+// estate.
+var benchmarkZones = []repository.NoFlyZone{
+	rectangle(20000, 20000, 20002, 20002),
+	rectangle(1000, 30000, 1050, 30020),
+	{Vertices: []repository.Point{{X: 40000, Y: 5000}, {X: 40100, Y: 5000}, {X: 40000, Y: 5100}}},
+	rectangle(10000, 45000, 30000, 45000),
+}
+
+// benchmarkBattery is the pad in the middle of the maximum size estate and a
+// range reaching its corners.
+var benchmarkBattery = Battery{Range: 2000000, Pad: Position{X: 25000, Y: 25000}}
+
+func BenchmarkCompute(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Compute(0)
+	}
+}
+
+func BenchmarkComputeMaxDistance(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	maxDistance := planner.Compute(0).Distance / 2
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Compute(maxDistance)
+	}
+}
+
+func BenchmarkComputeLookAhead(b *testing.B) {
+	planner := benchmarkPlanner(MaxLookAhead)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Compute(0)
+	}
+}
+
+func BenchmarkComputeZones(b *testing.B) {
+	planner := benchmarkPlanner(0, benchmarkZones...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Every planner finds the course around the zones again.
+		planner.course, planner.obstacles, planner.distances = nil, nil, nil
+		planner.Compute(0)
+	}
+}
+
+func BenchmarkSorties(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := planner.Sorties(benchmarkBattery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSortiesZones(b *testing.B) {
+	planner := benchmarkPlanner(0, benchmarkZones...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.course, planner.obstacles, planner.distances = nil, nil, nil
+		if _, err := planner.Sorties(benchmarkBattery); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRoute(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Route()
+	}
+}
@@ -0,0 +1,101 @@
+package droneplan
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotInSweep is returned when resuming from a plot the sweep does not
+	// survey, outside the estate or in a no-fly zone.
+	ErrNotInSweep = errors.New("plot is not surveyed by the sweep")
+	// ErrSweepCompleted is returned when resuming a sweep after it landed.
+	ErrSweepCompleted = errors.New("sweep is already completed")
+)
+
+// Resumption is the rest of a sweep interrupted above a plot.
+type Resumption struct {
+	// Start is the plot the sweep resumes from.
+	Start Position
+	// Transit is the flight from the ground of the pad to the start plot,
+	// reaching it at the altitude the sweep crosses it at.
+	Transit []Waypoint
+	// Distance is the distance in meters of the rest of the sweep from the
+	// start plot, landing included.
+	Distance int
+}
+
+// Resume returns the rest of the sweep from the start plot, flown after a
+// transit from the pad like a sortie, around the no-fly zones in the way. The
+// drone resumes from the first time the sweep reaches the plot. A tree tour
+// cannot be resumed.
+func (p *Planner) Resume(pad, start Position) (Resumption, error) {
+	if p.strategy == TreeTour {
+		return Resumption{}, fmt.Errorf("%w: only a sweep of the plots is resumed, not a %s", ErrUnsupportedStrategy, p.strategy)
+	}
+	if start.X < 1 || start.X > p.length || start.Y < 1 || start.Y > p.width {
+		return Resumption{}, ErrNotInSweep
+	}
+	if p.restricted(pad) {
+		return Resumption{}, ErrPadInNoFlyZone
+	}
+
+	origin := p.start()
+	distance := p.altitude(origin) - p.ground(origin)
+	reached, at := -1, hover{plot: origin, altitude: p.altitude(origin)}
+	if start == origin {
+		reached = distance
+	} else {
+		p.glide(func(from, to hover, steps int) bool {
+			// A level stretch reaches the plot on the way at the clearance.
+			if offset := p.index(start) - p.index(from.plot); steps > 1 && offset > 0 && offset <= steps {
+				reached, at = distance+PlotSize*offset, hover{plot: start, altitude: to.altitude}
+				return false
+			}
+			distance += PlotSize*steps + abs(to.altitude-from.altitude)
+			if to.plot == start {
+				reached, at = distance, to
+				return false
+			}
+			return true
+		})
+	}
+	if reached < 0 {
+		return Resumption{}, ErrNotInSweep
+	}
+
+	r := &route{waypoints: []Waypoint{{X: pad.X, Y: pad.Y, Altitude: p.ground(pad)}}}
+	if pad != start {
+		path := p.transit(pad, start)
+		if path == nil {
+			return Resumption{}, ErrPadEnclosed
+		}
+		r.fly(pad, p.ceiling)
+		for _, plot := range path {
+			r.fly(plot, p.ceiling)
+		}
+	}
+	r.fly(start, at.altitude)
+
+	return Resumption{
+		Start:    start,
+		Transit:  r.waypoints,
+		Distance: p.Compute(0).Distance - reached,
+	}, nil
+}
+
+// ResumeAfter returns the rest of the sweep interrupted after flying the
+// distance in meters, from the last plot reached within that distance or the
+// first plot of the sweep when nothing was flown.
+func (p *Planner) ResumeAfter(pad Position, flown int) (Resumption, error) {
+	if p.grounded() {
+		return Resumption{}, ErrEstateInNoFlyZones
+	}
+	if flown >= p.Compute(0).Distance {
+		return Resumption{}, ErrSweepCompleted
+	}
+	if flown < 1 {
+		return p.Resume(pad, p.start())
+	}
+	return p.Resume(pad, p.Compute(flown).Rest)
+}
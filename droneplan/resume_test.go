@@ -0,0 +1,169 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResume(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+
+	testcases := []struct {
+		name     string
+		estate   repository.Estate
+		trees    []repository.EstateTree
+		zones    []repository.NoFlyZone
+		pad      Position
+		start    *Position
+		flown    int
+		expected Resumption
+		err      error
+	}{
+		{
+			name:   "from a plot",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  sample,
+			start:  &Position{X: 3, Y: 1},
+			// 41 meters flown of 82 to reach the plot, 21 up and 20 across
+			expected: Resumption{
+				Start: Position{X: 3, Y: 1},
+				Transit: []Waypoint{
+					{X: 1, Y: 1, Altitude: 0, Distance: 0},
+					{X: 1, Y: 1, Altitude: 21, Distance: 21},
+					{X: 3, Y: 1, Altitude: 21, Distance: 41},
+				},
+				Distance: 41,
+			},
+		},
+		{
+			name:   "after a distance",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  sample,
+			flown:  50,
+			expected: Resumption{
+				Start: Position{X: 3, Y: 1},
+				Transit: []Waypoint{
+					{X: 1, Y: 1, Altitude: 0, Distance: 0},
+					{X: 1, Y: 1, Altitude: 21, Distance: 21},
+					{X: 3, Y: 1, Altitude: 21, Distance: 41},
+				},
+				Distance: 41,
+			},
+		},
+		{
+			name:   "from a plot flown over level",
+			estate: repository.Estate{Length: 3, Width: 3},
+			start:  &Position{X: 1, Y: 2},
+			expected: Resumption{
+				Start: Position{X: 1, Y: 2},
+				Transit: []Waypoint{
+					{X: 1, Y: 1, Altitude: 0, Distance: 0},
+					{X: 1, Y: 1, Altitude: 1, Distance: 1},
+					{X: 1, Y: 2, Altitude: 1, Distance: 11},
+				},
+				Distance: 31,
+			},
+		},
+		{
+			name:   "from the first plot",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  sample,
+			start:  &Position{X: 1, Y: 1},
+			expected: Resumption{
+				Start: Position{X: 1, Y: 1},
+				Transit: []Waypoint{
+					{X: 1, Y: 1, Altitude: 0, Distance: 0},
+					{X: 1, Y: 1, Altitude: 1, Distance: 1},
+				},
+				Distance: 81,
+			},
+		},
+		{
+			name:   "plot in a zone",
+			estate: repository.Estate{Length: 3, Width: 3},
+			zones:  []repository.NoFlyZone{rectangle(2, 2, 2, 2)},
+			start:  &Position{X: 2, Y: 2},
+			err:    ErrNotInSweep,
+		},
+		{
+			name:   "around a zone in the way",
+			estate: repository.Estate{Length: 5, Width: 5},
+			zones:  []repository.NoFlyZone{rectangle(3, 1, 3, 4)},
+			start:  &Position{X: 5, Y: 1},
+			expected: Resumption{
+				Start: Position{X: 5, Y: 1},
+				Transit: []Waypoint{
+					{X: 1, Y: 1, Altitude: 0, Distance: 0},
+					{X: 1, Y: 1, Altitude: 1, Distance: 1},
+					{X: 2, Y: 1, Altitude: 1, Distance: 11},
+					{X: 2, Y: 5, Altitude: 1, Distance: 51},
+					{X: 5, Y: 5, Altitude: 1, Distance: 81},
+					{X: 5, Y: 1, Altitude: 1, Distance: 121},
+				},
+				Distance: 321,
+			},
+		},
+		{
+			name:   "pad in a zone",
+			estate: repository.Estate{Length: 3, Width: 3},
+			zones:  []repository.NoFlyZone{rectangle(1, 1, 1, 1)},
+			start:  &Position{X: 3, Y: 3},
+			err:    ErrPadInNoFlyZone,
+		},
+		{
+			name:   "pad enclosed by zones",
+			estate: repository.Estate{Length: 3, Width: 3},
+			zones:  []repository.NoFlyZone{rectangle(2, 3, 2, 3), rectangle(3, 2, 3, 2)},
+			pad:    Position{X: 3, Y: 3},
+			start:  &Position{X: 1, Y: 1},
+			err:    ErrPadEnclosed,
+		},
+		{
+			name:   "plot outside the estate",
+			estate: repository.Estate{Length: 3, Width: 3},
+			start:  &Position{X: 4, Y: 1},
+			err:    ErrNotInSweep,
+		},
+		{
+			name:   "after landing",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  sample,
+			flown:  82,
+			err:    ErrSweepCompleted,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: tc.estate,
+				Trees:  tc.trees,
+				Zones:  tc.zones,
+			})
+
+			pad := tc.pad
+			if pad == (Position{}) {
+				pad = Position{X: 1, Y: 1}
+			}
+			var resumption Resumption
+			var err error
+			if tc.start != nil {
+				resumption, err = planner.Resume(pad, *tc.start)
+			} else {
+				resumption, err = planner.ResumeAfter(pad, tc.flown)
+			}
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, resumption)
+		})
+	}
+}
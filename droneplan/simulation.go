@@ -0,0 +1,62 @@
+package droneplan
+
+import "math"
+
+// Frame is the state of a simulated flight when the drone reaches a waypoint
+// of its route.
+type Frame struct {
+	// Index is the index of the waypoint in the route.
+	Index    int
+	Waypoint Waypoint
+	// Elapsed is the flight time in seconds since take off.
+	Elapsed float64
+	// Battery is the remaining battery range in meters.
+	Battery int
+}
+
+// Simulate flies the drone along the route and returns a frame for every
+// waypoint it reaches before its battery runs out. The drone hovers above
+// every plot it reaches, the take off plot included, like Estimate assumes.
+func Simulate(route []Waypoint, performance Performance, batteryRange int) ([]Frame, error) {
+	if err := performance.Validate(); err != nil {
+		return nil, err
+	}
+
+	frames := make([]Frame, 0, len(route))
+	elapsed := 0.0
+	for i, waypoint := range route {
+		if waypoint.Distance > batteryRange {
+			break
+		}
+
+		if i > 0 {
+			elapsed += performance.duration(route[i-1], waypoint)
+			if i == 1 {
+				elapsed += performance.HoverTime
+			}
+		}
+
+		frames = append(frames, Frame{
+			Index:    i,
+			Waypoint: waypoint,
+			Elapsed:  elapsed,
+			Battery:  batteryRange - waypoint.Distance,
+		})
+	}
+
+	return frames, nil
+}
+
+// duration returns the time in seconds to fly straight from a waypoint to the
+// next one, hovering above every plot reached on the way.
+func (performance Performance) duration(from, to Waypoint) float64 {
+	plots := math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+	duration := PlotSize*plots/performance.Speed + plots*performance.HoverTime
+
+	if climb := to.Altitude - from.Altitude; climb > 0 {
+		duration += float64(climb) / performance.ClimbRate
+	} else {
+		duration += float64(-climb) / performance.DescentRate
+	}
+	return duration
+}
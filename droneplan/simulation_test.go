@@ -0,0 +1,69 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate(t *testing.T) {
+	planner := NewPlanner(NewPlannerOptions{
+		Estate: repository.Estate{Length: 5, Width: 1},
+		Trees: []repository.EstateTree{
+			tree(2, 1, 10),
+			tree(3, 1, 20),
+			tree(4, 1, 10),
+		},
+	})
+	route := planner.Route()
+
+	testcases := []struct {
+		name         string
+		performance  Performance
+		batteryRange int
+		frames       int
+		battery      int
+	}{
+		{
+			name:         "whole route",
+			performance:  DefaultPerformance,
+			batteryRange: 100,
+			frames:       len(route),
+			battery:      18,
+		},
+		{
+			name:         "hover above every plot",
+			performance:  Performance{Speed: 10, ClimbRate: 3, DescentRate: 2, HoverTime: 2, Power: 250},
+			batteryRange: 82,
+			frames:       len(route),
+			battery:      0,
+		},
+		{
+			name:         "battery runs out",
+			performance:  DefaultPerformance,
+			batteryRange: 50,
+			frames:       4,
+			battery:      19,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			frames, err := Simulate(route, tc.performance, tc.batteryRange)
+			require.NoError(t, err)
+			require.Len(t, frames, tc.frames)
+
+			last := frames[len(frames)-1]
+			require.Equal(t, tc.battery, last.Battery)
+			if tc.frames == len(route) {
+				estimate, err := planner.Estimate(0, tc.performance)
+				require.NoError(t, err)
+				require.InDelta(t, estimate.Duration, last.Elapsed, 1e-9)
+			}
+		})
+	}
+
+	_, err := Simulate(route, Performance{}, 100)
+	require.ErrorIs(t, err, ErrInvalidPerformance)
+}
@@ -0,0 +1,128 @@
+package droneplan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrBatteryRangeTooShort is returned when a single battery cannot fly
+	// from the pad to a plot and back.
+	ErrBatteryRangeTooShort = errors.New("battery range is too short")
+	// ErrPadEnclosed is returned when no-fly zones cut the pad off from the
+	// plots of the sweep.
+	ErrPadEnclosed = errors.New("launch pad is enclosed by no-fly zones")
+)
+
+// Battery is the range of the drone on a single battery and the pad it takes
+// off from and lands on to swap batteries.
+type Battery struct {
+	// Range is the distance in meters the drone can fly on one battery.
+	Range int
+	// Pad is the plot of the launch and landing pad.
+	Pad Position
+}
+
+// Sortie is a flight on one battery, from the pad to a segment of the sweep
+// and back to the pad.
+type Sortie struct {
+	// Start is the first plot of the sweep covered by the sortie.
+	Start Position
+	// End is the last plot of the sweep covered by the sortie.
+	End Position
+	// Distance is the distance in meters flown, including the flights from
+	// and back to the pad.
+	Distance int
+}
+
+// Sorties splits the sweep of the estate into flights that each fit in the
+// battery range. Every sortie takes off from the pad, resumes the sweep where
+// the previous one stopped and returns to the pad when the battery would not
+// allow it to come back after the next plot. The flights from and back to the
+// pad go around the no-fly zones. A tree tour is not split.
+func (p *Planner) Sorties(battery Battery) ([]Sortie, error) {
+	if p.strategy == TreeTour {
+		return nil, fmt.Errorf("%w: sorties resume a sweep of the plots, not a %s", ErrUnsupportedStrategy, p.strategy)
+	}
+	if p.restricted(battery.Pad) {
+		return nil, ErrPadInNoFlyZone
+	}
+	if p.grounded() {
+		return nil, ErrEstateInNoFlyZones
+	}
+	if start := p.start(); len(p.zones) > 0 && start != battery.Pad && p.transit(battery.Pad, start) == nil {
+		return nil, ErrPadEnclosed
+	}
+
+	var sorties []Sortie
+	var err error
+	launch := func(to hover) Sortie {
+		if 2*p.ferry(battery.Pad, to) > battery.Range {
+			err = fmt.Errorf("%w to survey plot (%d, %d) from the pad", ErrBatteryRangeTooShort, to.plot.X, to.plot.Y)
+		}
+		return Sortie{Start: to.plot, End: to.plot, Distance: p.ferry(battery.Pad, to)}
+	}
+
+	start := p.start()
+	last := hover{plot: start, altitude: p.altitude(start)}
+	sortie := launch(last)
+	if err != nil {
+		return nil, err
+	}
+
+	advance := func(from, to hover) bool {
+		step := PlotSize + abs(to.altitude-from.altitude)
+		if sortie.Distance+step+p.ferry(battery.Pad, to) <= battery.Range {
+			sortie.Distance += step
+			sortie.End, last = to.plot, to
+			return true
+		}
+
+		sortie.Distance += p.ferry(battery.Pad, from)
+		sorties = append(sorties, sortie)
+		sortie, last = launch(to), to
+		return err == nil
+	}
+
+	p.glide(func(from, to hover, steps int) bool {
+		if steps == 1 {
+			return advance(from, to)
+		}
+
+		// Along a level stretch the distance flown plus the ferry back grows
+		// with every plot but the pad, so the plots the battery allows up to
+		// the pad are searched for.
+		index, pad := p.index(from.plot), p.index(battery.Pad)-p.index(from.plot)
+		plot := func(i int) hover {
+			return hover{plot: p.position(index + i), altitude: from.altitude}
+		}
+		for done := 0; done < steps; {
+			end := steps
+			if pad > done && pad <= steps {
+				end = pad - 1
+			}
+			fits := done + sort.Search(end-done, func(i int) bool {
+				return sortie.Distance+PlotSize*(i+1)+p.ferry(battery.Pad, plot(done+i+1)) > battery.Range
+			})
+			if fits > done {
+				sortie.Distance += PlotSize * (fits - done)
+				sortie.End, last = plot(fits).plot, plot(fits)
+			}
+			if fits == steps {
+				break
+			}
+			if !advance(plot(fits), plot(fits+1)) {
+				return false
+			}
+			done = fits + 1
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortie.Distance += p.ferry(battery.Pad, last)
+	return append(sorties, sortie), nil
+}
@@ -0,0 +1,104 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSorties(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+
+	testcases := []struct {
+		name     string
+		estate   repository.Estate
+		trees    []repository.EstateTree
+		zones    []repository.NoFlyZone
+		battery  Battery
+		expected []Sortie
+		err      error
+	}{
+		{
+			name:    "single sortie returns to the pad",
+			estate:  repository.Estate{Length: 5, Width: 1},
+			trees:   sample,
+			battery: Battery{Range: 200, Pad: Position{X: 1, Y: 1}},
+			expected: []Sortie{
+				// 81 to sweep up to (5, 1), then 21 + 40 + 20 back to the pad
+				{Start: Position{X: 1, Y: 1}, End: Position{X: 5, Y: 1}, Distance: 162},
+			},
+		},
+		{
+			name:    "sorties resume the sweep from a pad in the middle",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			battery: Battery{Range: 60, Pad: Position{X: 2, Y: 2}},
+			expected: []Sortie{
+				{Start: Position{X: 1, Y: 1}, End: Position{X: 2, Y: 1}, Distance: 42},
+				{Start: Position{X: 3, Y: 1}, End: Position{X: 2, Y: 2}, Distance: 42},
+				{Start: Position{X: 1, Y: 2}, End: Position{X: 2, Y: 3}, Distance: 42},
+				{Start: Position{X: 3, Y: 3}, End: Position{X: 3, Y: 3}, Distance: 42},
+			},
+		},
+		{
+			name:    "ferry around a zone",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			zones:   []repository.NoFlyZone{rectangle(2, 1, 2, 2)},
+			battery: Battery{Range: 150, Pad: Position{X: 1, Y: 1}},
+			expected: []Sortie{
+				// Around the zone to (3, 1), back along (3, 3) to (1, 3)
+				// and 21 down the first column to the pad
+				{Start: Position{X: 1, Y: 1}, End: Position{X: 1, Y: 3}, Distance: 142},
+				// 31 to (2, 3) and 41 back along the first column
+				{Start: Position{X: 2, Y: 3}, End: Position{X: 3, Y: 3}, Distance: 82},
+			},
+		},
+		{
+			name:    "range too short for the ferry around a zone",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			zones:   []repository.NoFlyZone{rectangle(2, 1, 2, 2)},
+			battery: Battery{Range: 120, Pad: Position{X: 1, Y: 1}},
+			err:     ErrBatteryRangeTooShort,
+		},
+		{
+			name:    "pad in a zone",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			zones:   []repository.NoFlyZone{rectangle(2, 1, 2, 2)},
+			battery: Battery{Range: 200, Pad: Position{X: 2, Y: 2}},
+			err:     ErrPadInNoFlyZone,
+		},
+		{
+			name:    "pad enclosed by zones",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			zones:   []repository.NoFlyZone{rectangle(1, 2, 3, 2)},
+			battery: Battery{Range: 200, Pad: Position{X: 1, Y: 3}},
+			err:     ErrPadEnclosed,
+		},
+		{
+			name:    "range too short for the first plot",
+			estate:  repository.Estate{Length: 3, Width: 3},
+			battery: Battery{Range: 41, Pad: Position{X: 2, Y: 2}},
+			err:     ErrBatteryRangeTooShort,
+		},
+		{
+			name:    "range too short for a plot far from the pad",
+			estate:  repository.Estate{Length: 5, Width: 1},
+			trees:   sample,
+			battery: Battery{Range: 130, Pad: Position{X: 1, Y: 1}},
+			err:     ErrBatteryRangeTooShort,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{Estate: tc.estate, Trees: tc.trees, Zones: tc.zones})
+			sorties, err := planner.Sorties(tc.battery)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, sorties)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package droneplan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+)
+
+var (
+	// ErrInvalidDosing is returned when a dosing is negative or gives no
+	// chemical at all.
+	ErrInvalidDosing = errors.New("invalid dosing")
+	// ErrTankTooSmall is returned when a single tree needs more chemical than
+	// the tank holds.
+	ErrTankTooSmall = errors.New("tank capacity is too small")
+)
+
+// Dosing is the formula of the chemical sprayed on a tree from its height.
+type Dosing struct {
+	// Base is the dose in litres of every tree.
+	Base float64
+	// PerMeter is the dose in litres added for every meter of height.
+	PerMeter float64
+}
+
+// DefaultDosing is the dosing of the estates that did not configure one.
+var DefaultDosing = Dosing{Base: 0.5, PerMeter: 0.1}
+
+// Validate returns ErrInvalidDosing unless both terms are finite and not
+// negative and at least one of them is positive.
+func (d Dosing) Validate() error {
+	for _, term := range []float64{d.Base, d.PerMeter} {
+		if term < 0 || math.IsNaN(term) || math.IsInf(term, 0) {
+			return ErrInvalidDosing
+		}
+	}
+	if d.Base == 0 && d.PerMeter == 0 {
+		return ErrInvalidDosing
+	}
+	return nil
+}
+
+// Dose returns the litres sprayed on a tree of the height in meters.
+func (d Dosing) Dose(height int) float64 {
+	return d.Base + d.PerMeter*float64(height)
+}
+
+// Tank is the chemical tank of the drone.
+type Tank struct {
+	// Capacity is the litres the tank holds.
+	Capacity float64
+	// Refill is the plot the drone takes off from with a full tank and comes
+	// back to once it runs empty.
+	Refill Position
+}
+
+// SpraySortie is a flight from the refill point spraying trees until the tank
+// runs empty and back.
+type SpraySortie struct {
+	// Trees are the trees sprayed, in visiting order.
+	Trees []repository.EstateTree
+	// Litres is the chemical sprayed.
+	Litres float64
+	// Distance is the distance in meters flown from take off to landing.
+	Distance int
+}
+
+// Spraying is the plan spraying the trees of the estate.
+type Spraying struct {
+	Sorties []SpraySortie
+	// Skipped are the trees left out because no-fly zones cover or enclose
+	// them.
+	Skipped []repository.EstateTree
+	// Litres is the chemical sprayed by every sortie.
+	Litres float64
+	// Distance is the distance in meters flown by every sortie.
+	Distance int
+}
+
+// Spray plans the sorties spraying every tree with its dose. The trees are
+// sprayed in the order the sweep crosses them, hovering above each at the
+// clearance and flying between them like an inspection. A sortie takes off
+// from the refill point with a full tank and lands back on it once the next
+// tree needs more chemical than is left.
+func (p *Planner) Spray(trees []repository.EstateTree, dosing Dosing, tank Tank) (Spraying, error) {
+	var spraying Spraying
+	if err := dosing.Validate(); err != nil {
+		return spraying, err
+	}
+	if p.restricted(tank.Refill) {
+		return spraying, ErrPadInNoFlyZone
+	}
+
+	trees = slices.Clone(trees)
+	slices.SortFunc(trees, func(a, b repository.EstateTree) int {
+		return p.index(Position{X: a.X, Y: a.Y}) - p.index(Position{X: b.X, Y: b.Y})
+	})
+
+	refill := stop{plot: tank.Refill, altitude: p.ground(tank.Refill)}
+	at, r := refill, (*route)(nil)
+	var sortie SpraySortie
+	land := func() {
+		p.hop(r, at, refill)
+		sortie.Distance = r.waypoints[len(r.waypoints)-1].Distance
+		spraying.Sorties = append(spraying.Sorties, sortie)
+		spraying.Litres += sortie.Litres
+		spraying.Distance += sortie.Distance
+		at, r, sortie = refill, nil, SpraySortie{}
+	}
+
+	for _, tree := range trees {
+		plot := Position{X: tree.X, Y: tree.Y}
+		dose := dosing.Dose(tree.Height)
+		if dose > tank.Capacity {
+			return Spraying{}, fmt.Errorf("%w for the %.2f litres of the tree at (%d, %d)", ErrTankTooSmall, dose, tree.X, tree.Y)
+		}
+
+		// The drone only flies where it can come back from, a tree out of
+		// reach of the last stop is out of reach of the refill point too.
+		if p.restricted(plot) {
+			spraying.Skipped = append(spraying.Skipped, tree)
+			continue
+		}
+		if _, clear := p.overfly(at.plot, plot); !clear && p.detour(at.plot, plot) == nil {
+			spraying.Skipped = append(spraying.Skipped, tree)
+			continue
+		}
+
+		if r != nil && sortie.Litres+dose > tank.Capacity+epsilon {
+			land()
+		}
+		if r == nil {
+			r = &route{waypoints: []Waypoint{{X: tank.Refill.X, Y: tank.Refill.Y, Altitude: refill.altitude}}}
+		}
+
+		next := stop{plot: plot, altitude: p.altitude(plot), tree: tree}
+		p.hop(r, at, next)
+		at = next
+		sortie.Trees = append(sortie.Trees, tree)
+		sortie.Litres += dose
+	}
+	if r != nil {
+		land()
+	}
+
+	return spraying, nil
+}
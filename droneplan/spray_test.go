@@ -0,0 +1,116 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpray(t *testing.T) {
+	testcases := []struct {
+		name     string
+		estate   repository.Estate
+		trees    []repository.EstateTree
+		zones    []repository.NoFlyZone
+		dosing   Dosing
+		tank     Tank
+		expected Spraying
+		err      error
+	}{
+		{
+			name:   "single sortie in sweep order",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  []repository.EstateTree{tree(4, 1, 10), tree(3, 1, 20), tree(2, 1, 10)},
+			dosing: Dosing{Base: 1, PerMeter: 0.1},
+			tank:   Tank{Capacity: 10, Refill: Position{X: 1, Y: 1}},
+			expected: Spraying{
+				Sorties: []SpraySortie{
+					{Trees: []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)}, Litres: 7, Distance: 122},
+				},
+				Litres:   7,
+				Distance: 122,
+			},
+		},
+		{
+			name:   "refill when the tank runs empty",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+			dosing: Dosing{Base: 1, PerMeter: 0.1},
+			tank:   Tank{Capacity: 5, Refill: Position{X: 1, Y: 1}},
+			expected: Spraying{
+				Sorties: []SpraySortie{
+					// 11 up, 10 across, 10 up, 10 across and back at 21
+					{Trees: []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20)}, Litres: 5, Distance: 82},
+					// 21 up, 30 across, 10 down to the tree and back the same way
+					{Trees: []repository.EstateTree{tree(4, 1, 10)}, Litres: 2, Distance: 122},
+				},
+				Litres:   7,
+				Distance: 204,
+			},
+		},
+		{
+			name:   "detour around a zone and skip the trees inside",
+			estate: repository.Estate{Length: 5, Width: 5},
+			trees:  []repository.EstateTree{tree(5, 1, 0), tree(3, 2, 0)},
+			zones:  []repository.NoFlyZone{rectangle(3, 1, 3, 4)},
+			dosing: Dosing{Base: 1},
+			tank:   Tank{Capacity: 5, Refill: Position{X: 1, Y: 1}},
+			expected: Spraying{
+				Sorties: []SpraySortie{
+					// 12 plots through the gap at (3, 5) each way
+					{Trees: []repository.EstateTree{tree(5, 1, 0)}, Litres: 1, Distance: 242},
+				},
+				Skipped:  []repository.EstateTree{tree(3, 2, 0)},
+				Litres:   1,
+				Distance: 242,
+			},
+		},
+		{
+			name:   "no trees",
+			estate: repository.Estate{Length: 5, Width: 5},
+			dosing: DefaultDosing,
+			tank:   Tank{Capacity: 5, Refill: Position{X: 1, Y: 1}},
+		},
+		{
+			name:   "tree dose above the capacity",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20)},
+			dosing: Dosing{Base: 1, PerMeter: 0.1},
+			tank:   Tank{Capacity: 2.5, Refill: Position{X: 1, Y: 1}},
+			err:    ErrTankTooSmall,
+		},
+		{
+			name:   "no chemical",
+			estate: repository.Estate{Length: 5, Width: 1},
+			tank:   Tank{Capacity: 5, Refill: Position{X: 1, Y: 1}},
+			err:    ErrInvalidDosing,
+		},
+		{
+			name:   "refill point in a zone",
+			estate: repository.Estate{Length: 5, Width: 5},
+			zones:  []repository.NoFlyZone{rectangle(1, 1, 1, 1)},
+			dosing: DefaultDosing,
+			tank:   Tank{Capacity: 5, Refill: Position{X: 1, Y: 1}},
+			err:    ErrPadInNoFlyZone,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: tc.estate,
+				Trees:  tc.trees,
+				Zones:  tc.zones,
+			})
+
+			spraying, err := planner.Spray(tc.trees, tc.dosing, tc.tank)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, spraying)
+		})
+	}
+}
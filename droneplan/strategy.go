@@ -0,0 +1,210 @@
+package droneplan
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Strategy is the pattern the drone flies over the estate.
+type Strategy string
+
+const (
+	// Zigzag sweeps the estate line by line, turning back at the end of
+	// every line.
+	Zigzag Strategy = "zigzag"
+	// Spiral sweeps the estate ring by ring, from the start corner along the
+	// edges of the estate towards its center.
+	Spiral Strategy = "spiral"
+	// TreeTour only flies over the trees, in the order the zigzag crosses
+	// them, flying straight from tree to tree like an inspection.
+	TreeTour Strategy = "tree-tour"
+)
+
+// Strategies lists every strategy.
+var Strategies = []Strategy{Zigzag, Spiral, TreeTour}
+
+// ErrUnsupportedStrategy is returned when planning a flight the strategy of
+// the planner cannot fly.
+var ErrUnsupportedStrategy = errors.New("unsupported strategy")
+
+// Evaluation is the flight planned over the whole estate with a strategy.
+type Evaluation struct {
+	Strategy Strategy
+	Sweep    Sweep
+	// Distance is the total distance in meters flown.
+	Distance int
+	// Climb and Descent are the meters flown up and down.
+	Climb   int
+	Descent int
+	// Estimate is the duration and energy of the flight.
+	Estimate Estimate
+}
+
+// Evaluate returns the flight over the whole estate, to compare strategies.
+func (p *Planner) Evaluate(performance Performance) (Evaluation, error) {
+	estimate, err := p.Estimate(0, performance)
+	if err != nil {
+		return Evaluation{}, err
+	}
+
+	plan, flight := p.compute(0)
+	return Evaluation{
+		Strategy: p.strategy,
+		Sweep:    p.sweep,
+		Distance: plan.Distance,
+		Climb:    flight.climb,
+		Descent:  flight.descent,
+		Estimate: estimate,
+	}, nil
+}
+
+// ring returns the ring of the spiral the plot at the index belongs to, and
+// the index of the plot within the ring. Ring k is the edge of the rectangle
+// left once the k outer rings are removed, so it starts after
+// lines*plots - (lines-2k)*(plots-2k) plots.
+func (p *Planner) ring(index int) (ring, offset int) {
+	lines, plots := p.lines()
+	first := func(k int) int {
+		return lines*plots - (lines-2*k)*(plots-2*k)
+	}
+
+	ring = sort.Search((min(lines, plots)+1)/2, func(k int) bool {
+		return first(k) > index
+	}) - 1
+	return ring, index - first(ring)
+}
+
+// spiralPosition returns the plot at the index of the spiral. Every ring
+// runs along the first line from the start corner, along the last plots of
+// the lines, back along the last line and along the first plots of the lines
+// to the next ring.
+func (p *Planner) spiralPosition(index int) Position {
+	lines, plots := p.lines()
+	k, offset := p.ring(index)
+	h, w := lines-2*k, plots-2*k
+
+	switch {
+	case offset < w:
+		return p.plot(k, k+offset)
+	case offset < w+h-1:
+		return p.plot(k+1+offset-w, k+w-1)
+	case offset < 2*w+h-2:
+		return p.plot(k+h-1, k+w-2-(offset-w-h+1))
+	default:
+		return p.plot(k+h-2-(offset-2*w-h+2), k)
+	}
+}
+
+// spiralIndex returns the number of plots before the plot in the spiral.
+func (p *Planner) spiralIndex(plot Position) int {
+	lines, plots := p.lines()
+	line, step := p.line(plot), p.step(plot)
+	k := min(line, step, lines-1-line, plots-1-step)
+	h, w := lines-2*k, plots-2*k
+
+	first := lines*plots - h*w
+	switch {
+	case line == k:
+		return first + step - k
+	case step == k+w-1:
+		return first + w + line - k - 1
+	case line == k+h-1:
+		return first + w + h - 1 + k + w - 2 - step
+	default:
+		return first + 2*w + h - 2 + k + h - 2 - line
+	}
+}
+
+// spiralTurn returns the index of the last plot of the side of the ring the
+// plot at the index is on.
+func (p *Planner) spiralTurn(index int) int {
+	lines, plots := p.lines()
+	k, offset := p.ring(index)
+	h, w := lines-2*k, plots-2*k
+
+	for _, end := range []int{w - 1, w + h - 2, 2*w + h - 3} {
+		if offset <= end {
+			return index - offset + end
+		}
+	}
+	return index - offset + 2*(w+h) - 5
+}
+
+// stops returns the trees of the tree tour in the order the zigzag crosses
+// them, and the plots of the trees left out because no-fly zones cover or
+// enclose them.
+func (p *Planner) stops() (stops []stop, skipped []Position) {
+	plots := make([]Position, 0, len(p.trees))
+	for plot := range p.trees {
+		plots = append(plots, plot)
+	}
+	slices.SortFunc(plots, func(a, b Position) int {
+		return p.zigzagIndex(a) - p.zigzagIndex(b)
+	})
+
+	at := p.start()
+	stops = make([]stop, 0, len(plots))
+	for _, plot := range plots {
+		// A tree out of reach of the last stop is out of reach of the take
+		// off plot too.
+		if p.restricted(plot) {
+			skipped = append(skipped, plot)
+			continue
+		}
+		if _, clear := p.overfly(at, plot); !clear && p.detour(at, plot) == nil {
+			skipped = append(skipped, plot)
+			continue
+		}
+		stops = append(stops, stop{plot: plot, altitude: p.altitude(plot)})
+		at = plot
+	}
+	return stops, skipped
+}
+
+// tour flies the tree tour like compute, taking off from the first plot of
+// the sweep and landing on the last tree, and also returns its route. When
+// maxDistance is greater than 0 the drone rests above the last tree it can
+// reach within that distance.
+func (p *Planner) tour(maxDistance int) (Plan, legs, []Waypoint) {
+	start := p.start()
+	plan := Plan{Rest: start}
+	var flight legs
+
+	at := stop{plot: start, altitude: p.ground(start)}
+	r := &route{waypoints: []Waypoint{{X: start.X, Y: start.Y, Altitude: at.altitude}}}
+	stops, _ := p.stops()
+	completed := true
+	for _, next := range stops {
+		n, last, distance := len(r.waypoints), r.waypoints[len(r.waypoints)-1], r.distance
+		p.hop(r, at, next)
+		if maxDistance > 0 && r.waypoints[len(r.waypoints)-1].Distance > maxDistance {
+			r.waypoints, r.distance = r.waypoints[:n], distance
+			r.waypoints[n-1] = last
+			completed = false
+			break
+		}
+		at = next
+		plan.Rest = next.plot
+		flight.plots++
+	}
+	if completed {
+		r.fly(at.plot, p.ground(at.plot))
+	}
+
+	horizontal := 0.0
+	for i := 1; i < len(r.waypoints); i++ {
+		from, to := r.waypoints[i-1], r.waypoints[i]
+		horizontal += PlotSize * math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+		if to.Altitude > from.Altitude {
+			flight.climb += to.Altitude - from.Altitude
+		} else {
+			flight.descent += from.Altitude - to.Altitude
+		}
+	}
+	flight.horizontal = int(math.Round(horizontal))
+	plan.Distance = r.waypoints[len(r.waypoints)-1].Distance
+
+	return plan, flight, r.waypoints
+}
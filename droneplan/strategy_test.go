@@ -0,0 +1,184 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpiral(t *testing.T) {
+	planner := NewPlanner(NewPlannerOptions{
+		Estate:   repository.Estate{Length: 3, Width: 3},
+		Strategy: Spiral,
+	})
+	expected := []Position{
+		{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1},
+		{X: 3, Y: 2}, {X: 3, Y: 3}, {X: 2, Y: 3},
+		{X: 1, Y: 3}, {X: 1, Y: 2}, {X: 2, Y: 2},
+	}
+	for index, plot := range expected {
+		require.Equal(t, plot, planner.position(index))
+	}
+
+	for length := 1; length <= 7; length++ {
+		for width := 1; width <= 7; width++ {
+			for _, corner := range Corners {
+				for _, orientation := range Orientations {
+					planner := NewPlanner(NewPlannerOptions{
+						Estate:   repository.Estate{Length: length, Width: width},
+						Strategy: Spiral,
+						Sweep:    Sweep{Corner: corner, Orientation: orientation},
+					})
+
+					seen := make(map[Position]bool)
+					for index := 0; index < length*width; index++ {
+						plot := planner.position(index)
+						require.False(t, seen[plot])
+						seen[plot] = true
+						require.Equal(t, index, planner.index(plot))
+						if index > 0 {
+							require.Equal(t, 1, manhattan(planner.position(index-1), plot))
+						}
+
+						// The plots up to the turn are in a straight line.
+						turn := planner.turn(index)
+						require.GreaterOrEqual(t, turn, index)
+						require.Less(t, turn, length*width)
+						from, to := planner.position(index), planner.position(turn)
+						require.Equal(t, manhattan(from, to), turn-index)
+						require.True(t, from.X == to.X || from.Y == to.Y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTreeTour(t *testing.T) {
+	trees := []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)}
+
+	testcases := []struct {
+		name        string
+		estate      repository.Estate
+		trees       []repository.EstateTree
+		zones       []repository.NoFlyZone
+		maxDistance int
+		expected    Plan
+		waypoints   int
+		skipped     []Run
+	}{
+		{
+			name:   "fly over the trees only",
+			estate: repository.Estate{Length: 5, Width: 1},
+			trees:  trees,
+			// 11 up, 10 across, 10 up, 20 across, 10 down and 11 down
+			expected:  Plan{Distance: 72, Rest: Position{X: 4, Y: 1}},
+			waypoints: 6,
+		},
+		{
+			name:        "rest above the last tree within the max distance",
+			estate:      repository.Estate{Length: 5, Width: 1},
+			trees:       trees,
+			maxDistance: 50,
+			expected:    Plan{Distance: 41, Rest: Position{X: 3, Y: 1}},
+		},
+		{
+			name:     "no trees",
+			estate:   repository.Estate{Length: 5, Width: 5},
+			expected: Plan{Rest: Position{X: 1, Y: 1}},
+		},
+		{
+			name:   "skip the trees in a zone",
+			estate: repository.Estate{Length: 5, Width: 5},
+			trees:  []repository.EstateTree{tree(3, 2, 5), tree(5, 1, 5)},
+			zones:  []repository.NoFlyZone{rectangle(3, 1, 3, 4)},
+			// 1 up, 12 plots through the gap at (3, 5) at the ground
+			// clearance, 5 up before the tree and 6 down
+			expected:  Plan{Distance: 132, Rest: Position{X: 5, Y: 1}},
+			waypoints: 9,
+			skipped:   []Run{{Start: Position{X: 3, Y: 2}, End: Position{X: 3, Y: 2}}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:   tc.estate,
+				Trees:    tc.trees,
+				Zones:    tc.zones,
+				Strategy: TreeTour,
+			})
+
+			require.Equal(t, tc.expected, planner.Compute(tc.maxDistance))
+			require.Equal(t, tc.skipped, planner.Skipped())
+			if tc.maxDistance == 0 {
+				route := planner.Route()
+				require.Len(t, route, max(tc.waypoints, 1))
+				require.Equal(t, tc.expected.Distance, route[len(route)-1].Distance)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	testcases := []struct {
+		strategy Strategy
+		distance int
+		climb    int
+		descent  int
+	}{
+		{strategy: Zigzag, distance: 82, climb: 21, descent: 21},
+		{strategy: Spiral, distance: 82, climb: 21, descent: 21},
+		{strategy: TreeTour, distance: 72, climb: 21, descent: 21},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:   repository.Estate{Length: 5, Width: 1},
+				Trees:    []repository.EstateTree{tree(2, 1, 10), tree(3, 1, 20), tree(4, 1, 10)},
+				Strategy: tc.strategy,
+			})
+
+			evaluation, err := planner.Evaluate(DefaultPerformance)
+			require.NoError(t, err)
+			require.Equal(t, tc.strategy, evaluation.Strategy)
+			require.Equal(t, tc.distance, evaluation.Distance)
+			require.Equal(t, tc.climb, evaluation.Climb)
+			require.Equal(t, tc.descent, evaluation.Descent)
+			require.Positive(t, evaluation.Estimate.Duration)
+		})
+	}
+}
+
+func TestUnsupportedStrategy(t *testing.T) {
+	estate := repository.Estate{Length: 5, Width: 5}
+
+	_, err := NewPlanner(NewPlannerOptions{Estate: estate, Strategy: Spiral}).Partition(2)
+	require.ErrorIs(t, err, ErrUnsupportedStrategy)
+
+	tour := NewPlanner(NewPlannerOptions{Estate: estate, Strategy: TreeTour})
+	_, err = tour.Sorties(Battery{Range: 1000, Pad: Position{X: 1, Y: 1}})
+	require.ErrorIs(t, err, ErrUnsupportedStrategy)
+	_, err = tour.Resume(Position{X: 1, Y: 1}, Position{X: 2, Y: 1})
+	require.ErrorIs(t, err, ErrUnsupportedStrategy)
+}
+
+func BenchmarkComputeSpiral(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	planner.strategy = Spiral
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Compute(0)
+	}
+}
+
+func BenchmarkComputeTreeTour(b *testing.B) {
+	planner := benchmarkPlanner(0)
+	planner.strategy = TreeTour
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.Compute(0)
+	}
+}
@@ -0,0 +1,67 @@
+package droneplan
+
+// Corner is the corner of the estate the drone takes off from.
+type Corner string
+
+const (
+	SouthWest Corner = "south-west"
+	SouthEast Corner = "south-east"
+	NorthWest Corner = "north-west"
+	NorthEast Corner = "north-east"
+)
+
+// Corners lists every corner of the estate.
+var Corners = []Corner{SouthWest, SouthEast, NorthWest, NorthEast}
+
+// Orientation is the axis the lines of the zigzag run along.
+type Orientation string
+
+const (
+	AlongX Orientation = "x"
+	AlongY Orientation = "y"
+)
+
+// Orientations lists every orientation of the zigzag.
+var Orientations = []Orientation{AlongX, AlongY}
+
+// Sweep is the zigzag pattern the drone flies over the estate.
+type Sweep struct {
+	Corner      Corner
+	Orientation Orientation
+}
+
+// Alternative is the distance of the plan flown with a sweep.
+type Alternative struct {
+	Sweep    Sweep
+	Distance int
+}
+
+// Alternatives computes the plan of every combination of the corners and
+// orientations. The sweep of opts is ignored.
+func Alternatives(opts NewPlannerOptions, corners []Corner, orientations []Orientation) []Alternative {
+	alternatives := make([]Alternative, 0, len(corners)*len(orientations))
+	for _, corner := range corners {
+		for _, orientation := range orientations {
+			opts.Sweep = Sweep{Corner: corner, Orientation: orientation}
+			alternatives = append(alternatives, Alternative{
+				Sweep:    opts.Sweep,
+				Distance: NewPlanner(opts).Compute(0).Distance,
+			})
+		}
+	}
+
+	return alternatives
+}
+
+// Shortest returns the sweep of the shortest alternative, the first one when
+// several are as short.
+func Shortest(alternatives []Alternative) Sweep {
+	shortest := alternatives[0]
+	for _, alternative := range alternatives[1:] {
+		if alternative.Distance < shortest.Distance {
+			shortest = alternative
+		}
+	}
+
+	return shortest.Sweep
+}
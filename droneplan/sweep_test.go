@@ -0,0 +1,76 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlannerSweep(t *testing.T) {
+	estate := repository.Estate{Length: 3, Width: 2}
+
+	testcases := []struct {
+		name     string
+		sweep    Sweep
+		expected []Position
+	}{
+		{
+			name:     "default sweeps along x from south-west",
+			expected: []Position{{1, 1}, {2, 1}, {3, 1}, {3, 2}, {2, 2}, {1, 2}},
+		},
+		{
+			name:     "along x from north-east",
+			sweep:    Sweep{Corner: NorthEast, Orientation: AlongX},
+			expected: []Position{{3, 2}, {2, 2}, {1, 2}, {1, 1}, {2, 1}, {3, 1}},
+		},
+		{
+			name:     "along y from south-west",
+			sweep:    Sweep{Corner: SouthWest, Orientation: AlongY},
+			expected: []Position{{1, 1}, {1, 2}, {2, 2}, {2, 1}, {3, 1}, {3, 2}},
+		},
+		{
+			name:     "along y from south-east",
+			sweep:    Sweep{Corner: SouthEast, Orientation: AlongY},
+			expected: []Position{{3, 1}, {3, 2}, {2, 2}, {2, 1}, {1, 1}, {1, 2}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{Estate: estate, Sweep: tc.sweep})
+
+			plots := []Position{planner.start()}
+			planner.walk(func(from, to hover) bool {
+				plots = append(plots, to.plot)
+				return true
+			})
+			require.Equal(t, tc.expected, plots)
+		})
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	opts := NewPlannerOptions{
+		Estate: repository.Estate{Length: 3, Width: 3},
+		Trees: []repository.EstateTree{
+			tree(1, 1, 20),
+			tree(2, 1, 10),
+			tree(2, 2, 10),
+			tree(2, 3, 10),
+		},
+	}
+
+	alternatives := Alternatives(opts, Corners, Orientations)
+	require.Equal(t, []Alternative{
+		{Sweep: Sweep{Corner: SouthWest, Orientation: AlongX}, Distance: 162},
+		{Sweep: Sweep{Corner: SouthWest, Orientation: AlongY}, Distance: 142},
+		{Sweep: Sweep{Corner: SouthEast, Orientation: AlongX}, Distance: 162},
+		{Sweep: Sweep{Corner: SouthEast, Orientation: AlongY}, Distance: 122},
+		{Sweep: Sweep{Corner: NorthWest, Orientation: AlongX}, Distance: 162},
+		{Sweep: Sweep{Corner: NorthWest, Orientation: AlongY}, Distance: 122},
+		{Sweep: Sweep{Corner: NorthEast, Orientation: AlongX}, Distance: 162},
+		{Sweep: Sweep{Corner: NorthEast, Orientation: AlongY}, Distance: 142},
+	}, alternatives)
+	require.Equal(t, Sweep{Corner: SouthEast, Orientation: AlongY}, Shortest(alternatives))
+}
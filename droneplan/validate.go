@@ -0,0 +1,50 @@
+package droneplan
+
+import "github.com/fabrianivan-id/technical-test-sawitpro/repository"
+
+// RoutePoint is a waypoint of a route authored outside the planner, at plot
+// relative coordinates like a FlightSample and an altitude in meters.
+type RoutePoint struct {
+	X        float64
+	Y        float64
+	Altitude float64
+}
+
+// RouteCheck is the check of a route authored outside the planner against the
+// trees and the sweep of the estate.
+type RouteCheck struct {
+	// Report compares the route with the plan like a flight flown along it,
+	// the violations being the waypoints below the canopy and clearance and
+	// the legs between them crossing a plot below it.
+	Report
+	// OutOfBounds are the indexes of the waypoints outside the estate.
+	OutOfBounds []int
+	// Valid is true when the route covers every planned plot, every waypoint
+	// and every leg clears the plots below and no waypoint is outside the
+	// estate.
+	Valid bool
+}
+
+// CheckRoute checks the waypoints of a route, in flight order, like the
+// samples of a flight flown along it. A waypoint is inside the estate when it
+// is above one of its plots, up to their outer edges.
+func (p *Planner) CheckRoute(waypoints []RoutePoint) RouteCheck {
+	samples := make([]repository.FlightSample, 0, len(waypoints))
+	for _, waypoint := range waypoints {
+		samples = append(samples, repository.FlightSample{
+			X:        waypoint.X,
+			Y:        waypoint.Y,
+			Altitude: waypoint.Altitude,
+		})
+	}
+
+	check := RouteCheck{Report: p.Compare(samples)}
+	for i, waypoint := range waypoints {
+		if waypoint.X < 0.5 || waypoint.X > float64(p.length)+0.5 || waypoint.Y < 0.5 || waypoint.Y > float64(p.width)+0.5 {
+			check.OutOfBounds = append(check.OutOfBounds, i)
+		}
+	}
+	check.Valid = len(check.Missed) == 0 && len(check.Violations) == 0 && len(check.SegmentViolations) == 0 && len(check.OutOfBounds) == 0
+
+	return check
+}
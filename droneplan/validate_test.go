@@ -0,0 +1,113 @@
+package droneplan
+
+import (
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRoute(t *testing.T) {
+	sample := []repository.EstateTree{
+		tree(2, 1, 10),
+		tree(3, 1, 20),
+		tree(4, 1, 10),
+	}
+	route := func(points ...[3]float64) []RoutePoint {
+		waypoints := make([]RoutePoint, 0, len(points))
+		for _, point := range points {
+			waypoints = append(waypoints, RoutePoint{X: point[0], Y: point[1], Altitude: point[2]})
+		}
+		return waypoints
+	}
+
+	planner := NewPlanner(NewPlannerOptions{
+		Estate: repository.Estate{Length: 5, Width: 1},
+		Trees:  sample,
+	})
+	var planned [][3]float64
+	for _, waypoint := range planner.Route() {
+		planned = append(planned, [3]float64{float64(waypoint.X), float64(waypoint.Y), float64(waypoint.Altitude)})
+	}
+
+	testcases := []struct {
+		name        string
+		elevations  []repository.PlotElevation
+		trees       []repository.EstateTree
+		waypoints   []RoutePoint
+		covered     int
+		missed      []Run
+		violations  []int
+		segments    []SegmentViolation
+		outOfBounds []int
+		distance    float64
+		valid       bool
+	}{
+		{
+			name:      "planned route",
+			trees:     sample,
+			waypoints: route(planned...),
+			covered:   5,
+			distance:  82,
+			valid:     true,
+		},
+		{
+			name:       "waypoint below the tallest tree",
+			trees:      sample,
+			waypoints:  route([3]float64{1, 1, 0}, [3]float64{1, 1, 11}, [3]float64{3, 1, 11}, [3]float64{5, 1, 11}, [3]float64{5, 1, 0}),
+			covered:    5,
+			violations: []int{2},
+			distance:   62,
+		},
+		{
+			name:      "leg through the tallest tree",
+			trees:     sample,
+			waypoints: route([3]float64{1, 1, 0}, [3]float64{1, 1, 2}, [3]float64{5, 1, 2}, [3]float64{5, 1, 0}),
+			covered:   5,
+			segments: []SegmentViolation{
+				{Index: 2, Plot: Position{X: 3, Y: 1}, Altitude: 2, Required: 21},
+			},
+			distance: 2 + 40 + 2,
+		},
+		{
+			name:        "waypoint beyond the estate",
+			trees:       sample,
+			waypoints:   route([3]float64{1, 1, 0}, [3]float64{1, 1, 21}, [3]float64{6, 1, 21}, [3]float64{5.5, 1, 21}, [3]float64{5.5, 1, 0}),
+			covered:     5,
+			outOfBounds: []int{2},
+			distance:    21 + 50 + 5 + 21,
+		},
+		{
+			name:       "waypoints on the ground of raised terrain",
+			elevations: []repository.PlotElevation{{X: 1, Y: 1, Elevation: 10}, {X: 2, Y: 1, Elevation: 10}},
+			waypoints:  route([3]float64{1, 1, 10}, [3]float64{2, 1, 10}, [3]float64{2, 1, 11}, [3]float64{5, 1, 11}, [3]float64{5, 1, 0}),
+			covered:    4,
+			missed:     []Run{{Start: Position{X: 1, Y: 1}, End: Position{X: 1, Y: 1}}},
+			distance:   10 + 1 + 30 + 11,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate:     repository.Estate{Length: 5, Width: 1},
+				Trees:      tc.trees,
+				Elevations: tc.elevations,
+			})
+
+			check := planner.CheckRoute(tc.waypoints)
+			require.Equal(t, 5, check.PlannedPlots)
+			require.Equal(t, tc.covered, check.CoveredPlots)
+			require.Equal(t, tc.missed, check.Missed)
+			var violations []int
+			for _, violation := range check.Violations {
+				violations = append(violations, violation.Index)
+			}
+			require.Equal(t, tc.violations, violations)
+			require.Equal(t, tc.segments, check.SegmentViolations)
+			require.Equal(t, tc.outOfBounds, check.OutOfBounds)
+			require.InDelta(t, tc.distance, check.Distance, 1e-9)
+			require.Equal(t, tc.valid, check.Valid)
+		})
+	}
+}
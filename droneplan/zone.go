@@ -0,0 +1,730 @@
+package droneplan
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+)
+
+// ErrEstateInNoFlyZones is returned when no-fly zones cover the whole estate,
+// leaving the drone no plot to fly.
+var ErrEstateInNoFlyZones = errors.New("no-fly zones cover the whole estate")
+
+// zone is a no-fly zone, a polygon whose vertices are in plot coordinates.
+// A plot is inside the zone when its center is inside or on the edge of the
+// polygon.
+type zone []repository.Point
+
+// contains reports whether the center of the plot is inside the zone.
+func (z zone) contains(plot Position) bool {
+	x, y := float64(plot.X), float64(plot.Y)
+
+	inside := false
+	for i, j := 0, len(z)-1; i < len(z); j, i = i, i+1 {
+		a, b := z[j], z[i]
+
+		// On the edge between a and b.
+		cross := (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+		if cross == 0 && x >= min(a.X, b.X) && x <= max(a.X, b.X) && y >= min(a.Y, b.Y) && y <= max(a.Y, b.Y) {
+			return true
+		}
+
+		if (a.Y > y) != (b.Y > y) && x < a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// cut returns the first and last coordinates, from 1 to size, of the runs of
+// plots of a row of the estate whose centers are inside the zone, or of a
+// column when transposed. The plots between two crossings of the edges with
+// the row are all inside or all outside, so only the plots around the
+// crossings and one plot between two of them are looked up.
+func (z zone) cut(row, size int, transposed bool) [][2]int {
+	plot := func(i int) Position {
+		if transposed {
+			return Position{X: row, Y: i}
+		}
+		return Position{X: i, Y: row}
+	}
+	coordinates := func(vertex repository.Point) (x, y float64) {
+		if transposed {
+			return vertex.Y, vertex.X
+		}
+		return vertex.X, vertex.Y
+	}
+
+	y := float64(row)
+	var crossings []int
+	cross := func(x float64) {
+		crossings = append(crossings, min(max(int(math.Floor(x)), 1), size), min(max(int(math.Ceil(x)), 1), size))
+	}
+	for i, j := 0, len(z)-1; i < len(z); j, i = i, i+1 {
+		ax, ay := coordinates(z[j])
+		bx, by := coordinates(z[i])
+		switch {
+		case y < min(ay, by) || y > max(ay, by):
+		case ay == by:
+			cross(ax)
+			cross(bx)
+		default:
+			cross(ax + (y-ay)*(bx-ax)/(by-ay))
+		}
+	}
+	slices.Sort(crossings)
+	crossings = slices.Compact(crossings)
+
+	var cuts [][2]int
+	include := func(first, last int) {
+		if n := len(cuts); n > 0 && cuts[n-1][1] == first-1 {
+			cuts[n-1][1] = last
+			return
+		}
+		cuts = append(cuts, [2]int{first, last})
+	}
+	for i, x := range crossings {
+		if i > 0 && x > crossings[i-1]+1 && z.contains(plot(crossings[i-1]+1)) {
+			include(crossings[i-1]+1, x-1)
+		}
+		if z.contains(plot(x)) {
+			include(x, x)
+		}
+	}
+	return cuts
+}
+
+// bounds returns the lowest and highest plot coordinates of the vertices.
+func (z zone) bounds() (low, high repository.Point) {
+	low, high = z[0], z[0]
+	for _, vertex := range z[1:] {
+		low.X, low.Y = min(low.X, vertex.X), min(low.Y, vertex.Y)
+		high.X, high.Y = max(high.X, vertex.X), max(high.Y, vertex.Y)
+	}
+	return low, high
+}
+
+// shift moves the zone by the opposite of the offset.
+func (z zone) shift(offset Position) zone {
+	shifted := make(zone, 0, len(z))
+	for _, vertex := range z {
+		shifted = append(shifted, repository.Point{X: vertex.X - float64(offset.X), Y: vertex.Y - float64(offset.Y)})
+	}
+	return shifted
+}
+
+// restricted reports whether the drone must not fly over the plot.
+func (p *Planner) restricted(plot Position) bool {
+	for _, z := range p.zones {
+		if z.contains(plot) {
+			return true
+		}
+	}
+	return false
+}
+
+// span is a run of plots of a line of the sweep outside the no-fly zones, by
+// step, and the component of the estate it belongs to. The drone can fly
+// between two plots of the same component around the zones.
+type span struct {
+	first     int
+	last      int
+	component int
+}
+
+// obstacles are the plots of the sweep the no-fly zones cover, line by line.
+// Only the lines of the sweep crossing a zone are held, the lines between two
+// of them are entirely outside the zones.
+type obstacles struct {
+	// lines are the lines crossing a zone, in order, and spans their plots
+	// outside the zones.
+	lines []int
+	spans [][]span
+	// gaps are the components of the lines between the lines crossing a
+	// zone, gaps[i] being the lines right before lines[i], or -1 when there
+	// are none.
+	gaps []int
+	// gridLines and gridSteps are the lines and the steps next to the edges
+	// of the zones, in order. The plots between two of them are all outside
+	// the zones or all like the plots next to them.
+	gridLines []int
+	gridSteps []int
+}
+
+// zoneObstacles returns the plots of the sweep the no-fly zones cover, found
+// once in time proportional to the lines the zones cross.
+func (p *Planner) zoneObstacles() *obstacles {
+	if p.obstacles != nil {
+		return p.obstacles
+	}
+	lines, plots := p.lines()
+
+	crossed := make(map[int][]zone)
+	for _, z := range p.zones {
+		low, high := z.bounds()
+		from := Position{X: max(int(math.Ceil(low.X)), 1), Y: max(int(math.Ceil(low.Y)), 1)}
+		to := Position{X: min(int(math.Floor(high.X)), p.length), Y: min(int(math.Floor(high.Y)), p.width)}
+		if from.X > to.X || from.Y > to.Y {
+			continue
+		}
+		first, last := p.line(from), p.line(to)
+		for line := min(first, last); line <= max(first, last); line++ {
+			crossed[line] = append(crossed[line], z)
+		}
+	}
+
+	o := &obstacles{}
+	for line := range crossed {
+		o.lines = append(o.lines, line)
+	}
+	slices.Sort(o.lines)
+
+	// Every line is cut along the row or the column of the estate it runs on.
+	held := o.lines[:0]
+	for _, line := range o.lines {
+		origin := p.plot(line, 0)
+		row, size, transposed := origin.Y, p.length, false
+		if p.sweep.Orientation == AlongY {
+			row, size, transposed = origin.X, p.width, true
+		}
+		position := func(i int) Position {
+			if transposed {
+				return Position{X: row, Y: i}
+			}
+			return Position{X: i, Y: row}
+		}
+
+		var cuts [][2]int
+		for _, z := range crossed[line] {
+			for _, cut := range z.cut(row, size, transposed) {
+				first, last := p.step(position(cut[0])), p.step(position(cut[1]))
+				cuts = append(cuts, [2]int{min(first, last), max(first, last)})
+			}
+		}
+		if len(cuts) == 0 {
+			continue
+		}
+		slices.SortFunc(cuts, func(a, b [2]int) int {
+			return a[0] - b[0]
+		})
+
+		var spans []span
+		next := 0
+		for _, cut := range cuts {
+			if cut[0] > next {
+				spans = append(spans, span{first: next, last: cut[0] - 1})
+			}
+			next = max(next, cut[1]+1)
+		}
+		if next < plots {
+			spans = append(spans, span{first: next, last: plots - 1})
+		}
+		held = append(held, line)
+		o.spans = append(o.spans, spans)
+	}
+	o.lines = held
+
+	// The components are found by joining the spans and gaps next to each
+	// other.
+	var parent []int
+	node := func() int {
+		parent = append(parent, len(parent))
+		return len(parent) - 1
+	}
+	find := func(n int) int {
+		for parent[n] != n {
+			parent[n] = parent[parent[n]]
+			n = parent[n]
+		}
+		return n
+	}
+	join := func(a, b int) {
+		parent[find(a)] = find(b)
+	}
+
+	o.gaps = make([]int, len(o.lines)+1)
+	for i := range o.gaps {
+		first, last := 0, lines-1
+		if i > 0 {
+			first = o.lines[i-1] + 1
+		}
+		if i < len(o.lines) {
+			last = o.lines[i] - 1
+		}
+		o.gaps[i] = -1
+		if first <= last {
+			o.gaps[i] = node()
+		}
+	}
+	for i, spans := range o.spans {
+		for j := range spans {
+			spans[j].component = node()
+			for _, gap := range []int{o.gaps[i], o.gaps[i+1]} {
+				if gap >= 0 {
+					join(spans[j].component, gap)
+				}
+			}
+		}
+		if i == 0 || o.lines[i-1] != o.lines[i]-1 {
+			continue
+		}
+		previous := o.spans[i-1]
+		for j, k := 0, 0; j < len(previous) && k < len(spans); {
+			if previous[j].last >= spans[k].first && spans[k].last >= previous[j].first {
+				join(previous[j].component, spans[k].component)
+			}
+			if previous[j].last < spans[k].last {
+				j++
+			} else {
+				k++
+			}
+		}
+	}
+
+	for i := range o.gaps {
+		if o.gaps[i] >= 0 {
+			o.gaps[i] = find(o.gaps[i])
+		}
+	}
+	for _, spans := range o.spans {
+		for j := range spans {
+			spans[j].component = find(spans[j].component)
+		}
+	}
+
+	near := func(values []int, value, size int) []int {
+		for _, v := range []int{value - 1, value, value + 1} {
+			if v >= 0 && v < size {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	o.gridLines = []int{0, lines - 1}
+	for _, line := range o.lines {
+		o.gridLines = near(o.gridLines, line, lines)
+	}
+	o.gridSteps = []int{0, plots - 1}
+	for _, spans := range o.spans {
+		for _, s := range spans {
+			o.gridSteps = near(near(o.gridSteps, s.first, plots), s.last, plots)
+		}
+	}
+	o.gridLines, o.gridSteps = including(o.gridLines), including(o.gridSteps)
+
+	p.obstacles = o
+	return o
+}
+
+// including returns the values and the extra values in order, once each.
+func including(values []int, extra ...int) []int {
+	values = append(slices.Clone(values), extra...)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// component returns the component of the plot at the step of a line, or -1
+// when a zone covers it.
+func (o *obstacles) component(line, step int) int {
+	i, found := slices.BinarySearch(o.lines, line)
+	if !found {
+		return o.gaps[i]
+	}
+
+	spans := o.spans[i]
+	j := sort.Search(len(spans), func(j int) bool {
+		return spans[j].last >= step
+	})
+	if j < len(spans) && spans[j].first <= step {
+		return spans[j].component
+	}
+	return -1
+}
+
+// along calls visit for the runs of steps from first to last of a line, in
+// order, whose component is bad.
+func (o *obstacles) along(line, first, last int, bad func(component int) bool, visit func(first, last int)) {
+	i, found := slices.BinarySearch(o.lines, line)
+	if !found {
+		if bad(o.gaps[i]) {
+			visit(first, last)
+		}
+		return
+	}
+
+	next := first
+	for _, s := range o.spans[i] {
+		if s.last < first || bad(s.component) {
+			continue
+		}
+		if s.first > last {
+			break
+		}
+		if s.first > next {
+			visit(next, s.first-1)
+		}
+		next = max(next, s.last+1)
+	}
+	if next <= last {
+		visit(next, last)
+	}
+}
+
+// across calls visit for the runs of lines from first to last, in order,
+// whose plot at the step has a bad component.
+func (o *obstacles) across(step, first, last int, bad func(component int) bool, visit func(first, last int)) {
+	pending := [2]int{-1, -1}
+	mark := func(from, to int) {
+		if pending[0] >= 0 && pending[1] == from-1 {
+			pending[1] = to
+			return
+		}
+		if pending[0] >= 0 {
+			visit(pending[0], pending[1])
+		}
+		pending = [2]int{from, to}
+	}
+
+	i, _ := slices.BinarySearch(o.lines, first)
+	for line := first; line <= last; {
+		if i < len(o.lines) && o.lines[i] == line {
+			if bad(o.component(line, step)) {
+				mark(line, line)
+			}
+			i++
+			line++
+			continue
+		}
+
+		// The lines up to the next line crossing a zone.
+		end := last
+		if i < len(o.lines) {
+			end = min(end, o.lines[i]-1)
+		}
+		if bad(o.gaps[i]) {
+			mark(line, end)
+		}
+		line = end + 1
+	}
+	if pending[0] >= 0 {
+		visit(pending[0], pending[1])
+	}
+}
+
+// clear reports whether the straight run between two plots of the same line
+// or column stays out of the no-fly zones.
+func (p *Planner) clear(from, to Position) bool {
+	o := p.zoneObstacles()
+	restricted := func(component int) bool {
+		return component < 0
+	}
+	clear := true
+	found := func(first, last int) {
+		clear = false
+	}
+
+	a, b := [2]int{p.line(from), p.step(from)}, [2]int{p.line(to), p.step(to)}
+	if a[0] == b[0] {
+		o.along(a[0], min(a[1], b[1]), max(a[1], b[1]), restricted, found)
+	} else {
+		o.across(a[1], min(a[0], b[0]), max(a[0], b[0]), restricted, found)
+	}
+	return clear
+}
+
+// transit returns the plots the drone flies straight to one after the other
+// from a plot to another, the first plot excluded. The drone flies along the
+// grid axes unless both ways cross a no-fly zone, it then follows the
+// shortest way around them. It returns nil when the zones enclose the
+// destination.
+func (p *Planner) transit(from, to Position) []Position {
+	for _, corner := range []Position{{X: to.X, Y: from.Y}, {X: from.X, Y: to.Y}} {
+		if p.clear(from, corner) && p.clear(corner, to) {
+			return []Position{corner, to}
+		}
+	}
+	return p.around(from, to)
+}
+
+// around returns the corners of the shortest way from a plot to another
+// around the no-fly zones and the destination, or nil when the zones enclose
+// it. The way only turns on the lines and steps next to the edges of the
+// zones, so it is searched for on their grid rather than plot by plot.
+func (p *Planner) around(from, to Position) []Position {
+	o := p.zoneObstacles()
+	source, target := [2]int{p.line(from), p.step(from)}, [2]int{p.line(to), p.step(to)}
+	if component := o.component(source[0], source[1]); component < 0 || component != o.component(target[0], target[1]) {
+		return nil
+	}
+
+	// A node of the grid is the position of its step and its line in them.
+	lines := including(o.gridLines, source[0], target[0])
+	steps := including(o.gridSteps, source[1], target[1])
+	node := func(plot [2]int) Position {
+		i, _ := slices.BinarySearch(lines, plot[0])
+		j, _ := slices.BinarySearch(steps, plot[1])
+		return Position{X: j, Y: i}
+	}
+	distance := func(a, b Position) int {
+		return abs(lines[a.Y]-lines[b.Y]) + abs(steps[a.X]-steps[b.X])
+	}
+
+	start, end := node(source), node(target)
+	previous := map[Position]Position{start: start}
+	costs := map[Position]int{start: 0}
+	queue := &plotQueue{{plot: start, estimate: distance(start, end)}}
+	for queue.Len() > 0 {
+		queued := heap.Pop(queue).(queuedPlot)
+		current := queued.plot
+		if queued.steps > costs[current] {
+			continue
+		}
+		if current == end {
+			var nodes []Position
+			for n := end; n != start; n = previous[n] {
+				nodes = append(nodes, n)
+			}
+			slices.Reverse(nodes)
+
+			// Only the nodes the way turns at and the destination are kept.
+			var path []Position
+			before := start
+			for k, n := range nodes {
+				if k == len(nodes)-1 || (before.X == n.X) != (n.X == nodes[k+1].X) {
+					path = append(path, p.plot(lines[n.Y], steps[n.X]))
+				}
+				before = n
+			}
+			return path
+		}
+
+		for _, next := range []Position{
+			{X: current.X + 1, Y: current.Y},
+			{X: current.X - 1, Y: current.Y},
+			{X: current.X, Y: current.Y + 1},
+			{X: current.X, Y: current.Y - 1},
+		} {
+			if next.X < 0 || next.X >= len(steps) || next.Y < 0 || next.Y >= len(lines) || o.component(lines[next.Y], steps[next.X]) < 0 {
+				continue
+			}
+			cost := costs[current] + distance(current, next)
+			if known, seen := costs[next]; seen && known <= cost {
+				continue
+			}
+			costs[next], previous[next] = cost, current
+			heap.Push(queue, queuedPlot{plot: next, steps: cost, estimate: cost + distance(next, end)})
+		}
+	}
+	return nil
+}
+
+// distances are the lengths of the shortest ways from a plot around the
+// no-fly zones to the nodes of the grid of the edges of the zones, -1 for
+// the nodes the zones cover or enclose.
+type distances struct {
+	from  Position
+	lines []int
+	steps []int
+	costs []int
+}
+
+// distancesFrom returns the lengths of the shortest ways from a plot around
+// the no-fly zones, found once for the last plot asked for.
+func (p *Planner) distancesFrom(from Position) *distances {
+	if p.distances != nil && p.distances.from == from {
+		return p.distances
+	}
+
+	o := p.zoneObstacles()
+	d := &distances{
+		from:  from,
+		lines: including(o.gridLines, p.line(from)),
+		steps: including(o.gridSteps, p.step(from)),
+	}
+	d.costs = make([]int, len(d.lines)*len(d.steps))
+	for i := range d.costs {
+		d.costs[i] = -1
+	}
+	node := func(n Position) int {
+		return n.Y*len(d.steps) + n.X
+	}
+
+	i, _ := slices.BinarySearch(d.lines, p.line(from))
+	j, _ := slices.BinarySearch(d.steps, p.step(from))
+	start := Position{X: j, Y: i}
+	tentative := map[Position]int{start: 0}
+	queue := &plotQueue{{plot: start}}
+	for queue.Len() > 0 {
+		queued := heap.Pop(queue).(queuedPlot)
+		current := queued.plot
+		if d.costs[node(current)] >= 0 {
+			continue
+		}
+		d.costs[node(current)] = queued.steps
+
+		for _, next := range []Position{
+			{X: current.X + 1, Y: current.Y},
+			{X: current.X - 1, Y: current.Y},
+			{X: current.X, Y: current.Y + 1},
+			{X: current.X, Y: current.Y - 1},
+		} {
+			if next.X < 0 || next.X >= len(d.steps) || next.Y < 0 || next.Y >= len(d.lines) || d.costs[node(next)] >= 0 || o.component(d.lines[next.Y], d.steps[next.X]) < 0 {
+				continue
+			}
+			cost := queued.steps + abs(d.lines[next.Y]-d.lines[current.Y]) + abs(d.steps[next.X]-d.steps[current.X])
+			if known, seen := tentative[next]; seen && known <= cost {
+				continue
+			}
+			tentative[next] = cost
+			heap.Push(queue, queuedPlot{plot: next, steps: cost, estimate: cost})
+		}
+	}
+
+	p.distances = d
+	return d
+}
+
+// to returns the length of the shortest way to a plot, or -1 when the zones
+// cover or enclose it. The plots between the nodes of the grid around the
+// plot are all outside the zones, so the way ends flying straight from one
+// of the nodes.
+func (d *distances) to(p *Planner, plot Position) int {
+	around := func(values []int, value int) []int {
+		i, found := slices.BinarySearch(values, value)
+		if found {
+			return []int{i}
+		}
+		return []int{i - 1, i}
+	}
+
+	line, step := p.line(plot), p.step(plot)
+	if p.zoneObstacles().component(line, step) < 0 {
+		return -1
+	}
+
+	shortest := -1
+	for _, i := range around(d.lines, line) {
+		for _, j := range around(d.steps, step) {
+			cost := d.costs[i*len(d.steps)+j]
+			if cost < 0 {
+				continue
+			}
+			if cost += abs(d.lines[i]-line) + abs(d.steps[j]-step); shortest < 0 || cost < shortest {
+				shortest = cost
+			}
+		}
+	}
+	return shortest
+}
+
+// length returns the number of plots flown along the path from a plot.
+func length(from Position, path []Position) int {
+	plots := 0
+	for _, next := range path {
+		plots += manhattan(from, next)
+		from = next
+	}
+	return plots
+}
+
+// neighbours returns the plots next to the plot the drone may fly over.
+func (p *Planner) neighbours(plot Position) []Position {
+	neighbours := make([]Position, 0, 4)
+	for _, next := range []Position{
+		{X: plot.X + 1, Y: plot.Y},
+		{X: plot.X - 1, Y: plot.Y},
+		{X: plot.X, Y: plot.Y + 1},
+		{X: plot.X, Y: plot.Y - 1},
+	} {
+		if next.X < 1 || next.X > p.length || next.Y < 1 || next.Y > p.width || p.restricted(next) {
+			continue
+		}
+		neighbours = append(neighbours, next)
+	}
+	return neighbours
+}
+
+// detour returns the shortest chain of neighbouring plots leading from a plot
+// to another around the no-fly zones, the first plot excluded, or nil when
+// the zones enclose the destination.
+func (p *Planner) detour(from, to Position) []Position {
+	if p.unreachable[to] {
+		return nil
+	}
+
+	previous := map[Position]Position{from: from}
+	steps := map[Position]int{from: 0}
+	queue := &plotQueue{{plot: from, estimate: manhattan(from, to)}}
+
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(queuedPlot).plot
+		if current == to {
+			var path []Position
+			for plot := to; plot != from; plot = previous[plot] {
+				path = append(path, plot)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+
+		for _, next := range p.neighbours(current) {
+			if step, seen := steps[next]; seen && step <= steps[current]+1 {
+				continue
+			}
+			steps[next] = steps[current] + 1
+			previous[next] = current
+			heap.Push(queue, queuedPlot{plot: next, steps: steps[next], estimate: steps[next] + manhattan(next, to)})
+		}
+	}
+
+	// Every plot enclosed with the destination is unreachable as well.
+	enclosed := []Position{to}
+	p.unreachable[to] = true
+	for len(enclosed) > 0 {
+		plot := enclosed[len(enclosed)-1]
+		enclosed = enclosed[:len(enclosed)-1]
+		for _, next := range p.neighbours(plot) {
+			if !p.unreachable[next] {
+				p.unreachable[next] = true
+				enclosed = append(enclosed, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+func manhattan(a, b Position) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+type queuedPlot struct {
+	plot     Position
+	steps    int
+	estimate int
+}
+
+// plotQueue is a priority queue of plots by estimated number of steps, the
+// plots furthest from the start first among equal estimates so that open
+// ground is crossed straight to the destination.
+type plotQueue []queuedPlot
+
+func (q plotQueue) Len() int { return len(q) }
+func (q plotQueue) Less(i, j int) bool {
+	return q[i].estimate < q[j].estimate || (q[i].estimate == q[j].estimate && q[i].steps > q[j].steps)
+}
+func (q plotQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *plotQueue) Push(x any)   { *q = append(*q, x.(queuedPlot)) }
+func (q *plotQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,135 @@
+package droneplan
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func rectangle(fromX, fromY, toX, toY float64) repository.NoFlyZone {
+	return repository.NoFlyZone{Vertices: []repository.Point{
+		{X: fromX, Y: fromY},
+		{X: toX, Y: fromY},
+		{X: toX, Y: toY},
+		{X: fromX, Y: toY},
+	}}
+}
+
+func TestZoneContains(t *testing.T) {
+	square := zone(rectangle(2, 2, 3, 3).Vertices)
+	require.True(t, square.contains(Position{X: 2, Y: 2}))
+	require.True(t, square.contains(Position{X: 3, Y: 3}))
+	require.False(t, square.contains(Position{X: 1, Y: 1}))
+	require.False(t, square.contains(Position{X: 4, Y: 2}))
+
+	triangle := zone{{X: 0.5, Y: 0.5}, {X: 4.5, Y: 0.5}, {X: 0.5, Y: 4.5}}
+	require.True(t, triangle.contains(Position{X: 1, Y: 1}))
+	require.True(t, triangle.contains(Position{X: 2, Y: 2}))
+	require.False(t, triangle.contains(Position{X: 3, Y: 3}))
+	require.False(t, triangle.contains(Position{X: 4, Y: 4}))
+}
+
+func TestPlannerZones(t *testing.T) {
+	testcases := []struct {
+		name     string
+		zones    []repository.NoFlyZone
+		expected Plan
+		skipped  []Run
+	}{
+		{
+			name:     "detour around a zone in the middle",
+			zones:    []repository.NoFlyZone{rectangle(2, 2, 2, 2)},
+			expected: Plan{Distance: 102, Rest: Position{X: 3, Y: 3}},
+			skipped:  []Run{{Start: Position{X: 2, Y: 2}, End: Position{X: 2, Y: 2}}},
+		},
+		{
+			name:     "start next to a zone on the corner",
+			zones:    []repository.NoFlyZone{rectangle(1, 1, 1, 1)},
+			expected: Plan{Distance: 72, Rest: Position{X: 3, Y: 3}},
+			skipped:  []Run{{Start: Position{X: 1, Y: 1}, End: Position{X: 1, Y: 1}}},
+		},
+		{
+			name:     "skip plots enclosed by a zone",
+			zones:    []repository.NoFlyZone{rectangle(2, 1, 2, 3)},
+			expected: Plan{Distance: 22, Rest: Position{X: 1, Y: 3}},
+			skipped: []Run{
+				{Start: Position{X: 2, Y: 1}, End: Position{X: 3, Y: 1}},
+				{Start: Position{X: 3, Y: 2}, End: Position{X: 2, Y: 2}},
+				{Start: Position{X: 2, Y: 3}, End: Position{X: 3, Y: 3}},
+			},
+		},
+		{
+			name:  "zones over the whole estate",
+			zones: []repository.NoFlyZone{rectangle(1, 1, 3, 3)},
+			skipped: []Run{
+				{Start: Position{X: 1, Y: 1}, End: Position{X: 3, Y: 1}},
+				{Start: Position{X: 3, Y: 2}, End: Position{X: 1, Y: 2}},
+				{Start: Position{X: 1, Y: 3}, End: Position{X: 3, Y: 3}},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			planner := NewPlanner(NewPlannerOptions{
+				Estate: repository.Estate{Length: 3, Width: 3},
+				Zones:  tc.zones,
+			})
+
+			require.Equal(t, tc.expected, planner.Compute(0))
+			require.Equal(t, tc.skipped, planner.Skipped())
+
+			route := planner.Route()
+			if tc.expected == (Plan{}) {
+				require.Empty(t, route)
+				return
+			}
+			require.Equal(t, tc.expected.Distance, route[len(route)-1].Distance)
+			for _, waypoint := range route {
+				require.False(t, planner.restricted(Position{X: waypoint.X, Y: waypoint.Y}))
+			}
+		})
+	}
+}
+
+// TestTransit checks the way and its length found on the grid of the edges of
+// the no-fly zones against the detour found plot by plot.
+func TestTransit(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		estate := repository.Estate{Length: random.Intn(10) + 1, Width: random.Intn(10) + 1}
+		planner := NewPlanner(NewPlannerOptions{
+			Estate: estate,
+			Sweep:  Sweep{Corner: Corners[random.Intn(len(Corners))], Orientation: Orientations[random.Intn(len(Orientations))]},
+			Zones:  randomZones(random, estate, random.Intn(4)+1),
+		})
+		from := Position{X: random.Intn(estate.Length) + 1, Y: random.Intn(estate.Width) + 1}
+		to := Position{X: random.Intn(estate.Length) + 1, Y: random.Intn(estate.Width) + 1}
+		if from == to || planner.restricted(from) || planner.restricted(to) {
+			continue
+		}
+
+		path := planner.transit(from, to)
+		detour := planner.detour(from, to)
+		require.Equal(t, detour == nil, path == nil)
+		if path == nil {
+			require.Equal(t, -1, planner.distancesFrom(from).to(planner, to))
+			continue
+		}
+		require.Equal(t, len(detour), planner.distancesFrom(from).to(planner, to))
+		require.Equal(t, len(detour), length(from, path))
+		require.Equal(t, to, path[len(path)-1])
+
+		at := from
+		for _, next := range path {
+			require.True(t, at.X == next.X || at.Y == next.Y)
+			for at != next {
+				at = Position{X: at.X + sign(next.X-at.X), Y: at.Y + sign(next.Y-at.Y)}
+				require.False(t, planner.restricted(at))
+			}
+		}
+	}
+}
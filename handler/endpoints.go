@@ -1,15 +1,49 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fabrianivan-id/technical-test-sawitpro/droneplan"
 	"github.com/fabrianivan-id/technical-test-sawitpro/generated"
 	"github.com/fabrianivan-id/technical-test-sawitpro/repository"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+// qgcPlanMIMEType is the media type of QGroundControl .plan mission files
+const qgcPlanMIMEType = "application/vnd.qgc.plan+json"
+
+// maxFlightSamples is the largest number of samples of an uploaded flight log
+const maxFlightSamples = 100000
+
+// maxPlotElevations is the largest number of plots of an imported elevation grid
+const maxPlotElevations = 1000000
+
+// maxRouteWaypoints is the largest number of waypoints of a validated route
+const maxRouteWaypoints = 100000
+
+// maxCaptures is the largest number of camera capture points listed
+const maxCaptures = 100000
+
+// dronePlanRevision is mixed into the version of stored drone plans, bump it
+// to discard them when the planner or the drone plan response changes
+const dronePlanRevision = 2
+
 // Handler to create a new estate
 // POST  /estate
 func (s *Server) CreateEstate(c echo.Context) error {
@@ -28,10 +62,40 @@ func (s *Server) CreateEstate(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, errResponse)
 	}
 
+	profile := droneplan.NewPlannerOptions{MaxAltitude: droneplan.DefaultMaxAltitude}
+	if req.MaxAltitude != nil {
+		if *req.MaxAltitude < 1 {
+			errResponse.Message = "Max altitude must be greater than 0"
+			return c.JSON(http.StatusBadRequest, errResponse)
+		}
+		profile.MaxAltitude = *req.MaxAltitude
+	}
+	if err := flightProfile(&profile, req.Clearance, req.LookAhead); err != nil {
+		errResponse.Message = err.Error()
+		return c.JSON(http.StatusBadRequest, errResponse)
+	}
+	if profile.Clearance == 0 {
+		profile.Clearance = droneplan.DefaultClearance
+	}
+
+	dosing := droneplan.DefaultDosing
+	if req.Dosing != nil {
+		dosing = droneplan.Dosing{Base: req.Dosing.Base, PerMeter: req.Dosing.PerMeter}
+		if err := dosing.Validate(); err != nil {
+			errResponse.Message = "Dosing must not be negative and must spray some chemical"
+			return c.JSON(http.StatusBadRequest, errResponse)
+		}
+	}
+
 	result, err := s.Repository.CreateEstate(ctx, repository.Estate{
-		Id:     uuid.New().String(),
-		Width:  req.Width,
-		Length: req.Length,
+		Id:           uuid.New().String(),
+		Width:        req.Width,
+		Length:       req.Length,
+		Clearance:    profile.Clearance,
+		LookAhead:    profile.LookAhead,
+		DoseBase:     dosing.Base,
+		DosePerMeter: dosing.PerMeter,
+		MaxAltitude:  profile.MaxAltitude,
 	})
 
 	if err != nil {
@@ -62,12 +126,82 @@ func (s *Server) CreateEstateIdTree(c echo.Context, id string) error {
 		return c.JSON(http.StatusBadRequest, errResponse)
 	}
 
+	var canopyDiameter float64
+	if req.CanopyDiameter != nil {
+		if *req.CanopyDiameter < 0 || *req.CanopyDiameter > droneplan.MaxCanopyDiameter {
+			errResponse.Message = fmt.Sprintf("Canopy diameter must be between 0 and %d", droneplan.MaxCanopyDiameter)
+			return c.JSON(http.StatusBadRequest, errResponse)
+		}
+		canopyDiameter = *req.CanopyDiameter
+	}
+
 	result, err := s.Repository.CreateEstateTree(ctx, repository.EstateTree{
+		Id:             uuid.New().String(),
+		EstateId:       id,
+		X:              req.X,
+		Y:              req.Y,
+		Height:         req.Height,
+		CanopyDiameter: canopyDiameter,
+	})
+
+	if err != nil {
+		errResponse.Message = err.Error()
+		return c.JSON(http.StatusBadRequest, errResponse)
+	}
+
+	return c.JSON(http.StatusCreated, generated.CreateTreeResponse{
+		Id: result.Id,
+	})
+}
+
+// Handler to create a new no-fly zone in an estate
+// POST  /estate/{id}/no-fly-zone
+func (s *Server) CreateEstateIdNoFlyZone(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	var req generated.CreateNoFlyZoneRequest
+	var errResponse generated.ErrorResponse
+
+	if err := c.Bind(&req); err != nil {
+		errResponse.Message = err.Error()
+		return c.JSON(http.StatusBadRequest, errResponse)
+	}
+
+	if (req.Rectangle == nil) == (req.Polygon == nil) {
+		errResponse.Message = "Either a rectangle or a polygon is required"
+		return c.JSON(http.StatusBadRequest, errResponse)
+	}
+
+	var vertices []repository.Point
+	if req.Rectangle != nil {
+		from, to := req.Rectangle.From, req.Rectangle.To
+		if from.X < 1 || from.Y < 1 || to.X < from.X || to.Y < from.Y {
+			errResponse.Message = "Invalid rectangle, from must be the south-west plot and to the north-east plot"
+			return c.JSON(http.StatusBadRequest, errResponse)
+		}
+
+		vertices = []repository.Point{
+			{X: float64(from.X), Y: float64(from.Y)},
+			{X: float64(to.X), Y: float64(from.Y)},
+			{X: float64(to.X), Y: float64(to.Y)},
+			{X: float64(from.X), Y: float64(to.Y)},
+		}
+	} else {
+		if len(*req.Polygon) < 3 {
+			errResponse.Message = "A polygon needs at least 3 vertices"
+			return c.JSON(http.StatusBadRequest, errResponse)
+		}
+
+		for _, vertex := range *req.Polygon {
+			vertices = append(vertices, repository.Point{X: vertex.X, Y: vertex.Y})
+		}
+	}
+
+	result, err := s.Repository.CreateNoFlyZone(ctx, repository.NoFlyZone{
 		Id:       uuid.New().String(),
 		EstateId: id,
-		X:        req.X,
-		Y:        req.Y,
-		Height:   req.Height,
+		Name:     req.Name,
+		Vertices: vertices,
 	})
 
 	if err != nil {
@@ -75,11 +209,42 @@ func (s *Server) CreateEstateIdTree(c echo.Context, id string) error {
 		return c.JSON(http.StatusBadRequest, errResponse)
 	}
 
-	return c.JSON(http.StatusCreated, generated.CreateTreeResponse{
+	return c.JSON(http.StatusCreated, generated.CreateNoFlyZoneResponse{
 		Id: result.Id,
 	})
 }
 
+// Handler to get the no-fly zones of an estate
+// GET  /estate/{id}/no-fly-zone
+func (s *Server) GetEstateIdNoFlyZones(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	result, err := s.Repository.GetNoFlyZonesByEstateId(ctx, id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	zones := make([]generated.NoFlyZone, 0, len(result))
+	for _, zone := range result {
+		vertices := make([]generated.Point, 0, len(zone.Vertices))
+		for _, vertex := range zone.Vertices {
+			vertices = append(vertices, generated.Point{X: vertex.X, Y: vertex.Y})
+		}
+
+		zones = append(zones, generated.NoFlyZone{
+			Id:       zone.Id,
+			Name:     zone.Name,
+			Vertices: vertices,
+		})
+	}
+
+	return c.JSON(http.StatusOK, generated.GetNoFlyZonesResponse{
+		Zones: zones,
+	})
+}
+
 // Handler to get estate stats
 // GET  /estate/{id}/stats
 func (s *Server) GetEstateIdStats(c echo.Context, id string) error {
@@ -100,41 +265,2202 @@ func (s *Server) GetEstateIdStats(c echo.Context, id string) error {
 	})
 }
 
-// Handler to get drone plan by estate id
-// GET  /estate/{id}/drone-plan
-func (s *Server) GetDronePlanByEstateId(c echo.Context, id string) error {
+// Handler to get the spraying dosing of an estate
+// GET  /estate/{id}/dosing
+func (s *Server) GetEstateIdDosing(c echo.Context, id string) error {
 	ctx := c.Request().Context()
 
-	estateData, err := s.Repository.GetEstateById(ctx, id)
+	estate, err := s.Repository.GetEstateById(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, generated.ErrorResponse{
-				Message: "Estate id not found",
+		return estateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, generated.SprayDosing{
+		Base:     estate.DoseBase,
+		PerMeter: estate.DosePerMeter,
+	})
+}
+
+// Handler to update the spraying dosing of an estate
+// PUT  /estate/{id}/dosing
+func (s *Server) UpdateEstateIdDosing(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	var req generated.SprayDosing
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Invalid Request Body",
+		})
+	}
+
+	if err := (droneplan.Dosing{Base: req.Base, PerMeter: req.PerMeter}).Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Dosing must not be negative and must spray some chemical",
+		})
+	}
+
+	err := s.Repository.UpdateEstateDosing(ctx, repository.Estate{
+		Id:           id,
+		DoseBase:     req.Base,
+		DosePerMeter: req.PerMeter,
+	})
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, req)
+}
+
+// Handler to import the terrain elevation of an estate
+// PUT  /estate/{id}/elevation
+func (s *Server) UpdateEstateIdElevation(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	var plots []repository.PlotElevation
+	var err error
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		plots, err = readElevationCSV(c.Request().Body)
+	} else {
+		plots, err = readElevationGrid(c.Request().Body)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	estate, err := s.Repository.GetEstateById(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	response := generated.ElevationSummary{Plots: len(plots)}
+	seen := make(map[droneplan.Position]bool, len(plots))
+	for _, plot := range plots {
+		if plot.X < 1 || plot.X > estate.Length || plot.Y < 1 || plot.Y > estate.Width {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: fmt.Sprintf("Plot (%d, %d) is out of the estate", plot.X, plot.Y),
+			})
+		}
+		position := droneplan.Position{X: plot.X, Y: plot.Y}
+		if seen[position] {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: fmt.Sprintf("Plot (%d, %d) has more than one elevation", plot.X, plot.Y),
 			})
 		}
+		seen[position] = true
+
+		if response.Min == nil || plot.Elevation < *response.Min {
+			response.Min = &plot.Elevation
+		}
+		if response.Max == nil || plot.Elevation > *response.Max {
+			response.Max = &plot.Elevation
+		}
+	}
 
+	if err := s.Repository.ReplacePlotElevations(ctx, id, plots); err != nil {
 		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
 			Message: err.Error(),
 		})
 	}
 
-	horizontalDistance := (estateData.Width-1)*estateData.Length + (estateData.Length-1)*estateData.Width
-	verticalDistance := 0
+	return c.JSON(http.StatusOK, response)
+}
+
+// Handler to get drone plan by estate id
+// GET  /estate/{id}/drone-plan
+func (s *Server) GetDronePlanByEstateId(c echo.Context, id string, params generated.GetDronePlanByEstateIdParams) error {
+	ctx := c.Request().Context()
 
-	treesData, err := s.Repository.GetTreesByEstateId(ctx, id)
-	if err != nil {
+	if params.Format != nil && *params.Format != generated.Qgc && *params.Format != generated.MavlinkWpl {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Format must be qgc or mavlink-wpl",
+		})
+	}
+
+	if err := s.dronePlanSpecs(ctx, &params); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+	if err := validateDronePlanParams(params); err != nil {
 		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
 			Message: err.Error(),
 		})
 	}
 
-	if len(treesData) > 0 {
-		for _, tree := range treesData {
-			verticalDistance += tree.Height
+	if format := missionFormat(c, params); format != "" {
+		opts, err := s.getPlannerOptions(ctx, id)
+		if err != nil {
+			return estateError(c, err)
+		}
+
+		if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: err.Error(),
+			})
+		}
+		if params.Strategy != nil {
+			opts.Strategy = droneplan.Strategy(*params.Strategy)
+		}
+
+		planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: err.Error(),
+			})
+		}
+
+		violations := ceilingViolationsResponse(planner.CeilingViolations())
+		if refused := ceilingRefusal(params, planner.MaxAltitude(), violations); refused != nil {
+			return c.JSON(http.StatusUnprocessableEntity, refused)
+		}
+
+		return exportDronePlan(c, id, planner, format, params)
+	}
+
+	plan, _, err := s.dronePlan(ctx, id, params)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	refused, err := refusedDronePlan(plan, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+	if refused != nil {
+		return c.JSON(http.StatusUnprocessableEntity, refused)
+	}
+
+	return c.JSONBlob(http.StatusOK, plan.Plan)
+}
+
+// validateDronePlanParams checks the distances, the launch position and the
+// drone performance of the drone plan parameters
+func validateDronePlanParams(params generated.GetDronePlanByEstateIdParams) error {
+	if params.MaxDistance != nil && *params.MaxDistance < 1 {
+		return errors.New("Max distance must be greater than 0")
+	}
+
+	if params.Strategy != nil && !slices.Contains(droneplan.Strategies, droneplan.Strategy(*params.Strategy)) {
+		return errors.New("Strategy must be zigzag, spiral or tree-tour")
+	}
+
+	if (params.BatteryRange != nil && *params.BatteryRange < 1) || (params.LaunchX != nil && *params.LaunchX < 1) || (params.LaunchY != nil && *params.LaunchY < 1) {
+		return errors.New("Battery range and launch position must be greater than 0")
+	}
+
+	if (params.ResumeX == nil) != (params.ResumeY == nil) || (params.ResumeX != nil && params.ResumeDistance != nil) {
+		return errors.New("Resume from either resume_x and resume_y or resume_distance")
+	}
+
+	if (params.ResumeX != nil && (*params.ResumeX < 1 || *params.ResumeY < 1)) || (params.ResumeDistance != nil && *params.ResumeDistance < 0) {
+		return errors.New("Resume position must be greater than 0 and resume distance must not be negative")
+	}
+
+	performance := dronePerformance(params.Speed, params.ClimbRate, params.DescentRate, params.HoverOverhead, params.Power)
+	if err := performance.Validate(); err != nil {
+		return errors.New("Speed, climb rate and descent rate must be greater than 0, hover overhead and power must not be negative")
+	}
+
+	return nil
+}
+
+// dronePlan returns the drone plan of the estate as the JSON drone plan
+// response, along with the estate it was planned over. Plans are stored by
+// version, unchanged trees, no-fly zones, elevations and parameters are served
+// without loading the trees and the elevations again. A missing estate is reported as sql.ErrNoRows.
+func (s *Server) dronePlan(ctx context.Context, id string, params generated.GetDronePlanByEstateIdParams) (result repository.DronePlan, estate repository.Estate, err error) {
+	opts, err := s.getEstateOptions(ctx, id)
+	if err != nil {
+		return
+	}
+	estate = opts.Estate
+
+	if err = flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return
+	}
+
+	version := dronePlanVersion(opts, params)
+	if stored, err := s.Repository.GetDronePlan(ctx, id, version); err == nil {
+		return stored, estate, nil
+	}
+
+	opts.Trees, err = s.Repository.GetTreesByEstateId(ctx, id)
+	if err != nil {
+		return
+	}
+	opts.Elevations, err = s.Repository.GetPlotElevationsByEstateId(ctx, id)
+	if err != nil {
+		return
+	}
+	if params.Strategy != nil {
+		opts.Strategy = droneplan.Strategy(*params.Strategy)
+	}
+
+	planner, alternatives, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return
+	}
+
+	maxDistance := 0
+	if params.MaxDistance != nil {
+		maxDistance = *params.MaxDistance
+	}
+
+	plan := planner.Compute(maxDistance)
+	estimate, err := planner.Estimate(maxDistance, dronePerformance(params.Speed, params.ClimbRate, params.DescentRate, params.HoverOverhead, params.Power))
+	if err != nil {
+		return
+	}
+
+	response := generated.GetDronePlanResponse{
+		Version:           version,
+		Distance:          plan.Distance,
+		NaiveDistance:     naiveDistance(opts, planner.Sweep(), maxDistance),
+		Estimate:          estimateResponse(estimate),
+		Strategy:          generated.Strategy(planner.Strategy()),
+		Sweep:             sweepResponse(planner.Sweep()),
+		Skipped:           skippedResponse(planner.Skipped()),
+		MaxAltitude:       planner.MaxAltitude(),
+		CeilingViolations: ceilingViolationsResponse(planner.CeilingViolations()),
+	}
+	if alternatives != nil {
+		alternativesData := make([]generated.DroneSweepAlternative, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			alternativesData = append(alternativesData, generated.DroneSweepAlternative{
+				StartCorner: string(alternative.Sweep.Corner),
+				Orientation: string(alternative.Sweep.Orientation),
+				Distance:    alternative.Distance,
+			})
+		}
+		response.Alternatives = &alternativesData
+	}
+	if params.MaxDistance != nil && plan != (droneplan.Plan{}) {
+		rest := plotResponse(plan.Rest)
+		response.Rest = &rest
+	}
+
+	pad := droneplan.Position{X: 1, Y: 1}
+	if params.LaunchX != nil {
+		pad.X = *params.LaunchX
+	}
+	if params.LaunchY != nil {
+		pad.Y = *params.LaunchY
+	}
+	if pad.X > opts.Estate.Length || pad.Y > opts.Estate.Width {
+		err = errors.New("Launch position must be inside the estate")
+		return
+	}
+
+	if params.BatteryRange != nil {
+		battery := droneplan.Battery{
+			Range: *params.BatteryRange,
+			Pad:   pad,
+		}
+
+		sorties, sortiesErr := planner.Sorties(battery)
+		if sortiesErr != nil {
+			err = sortiesErr
+			return
+		}
+
+		sortiesData := make([]generated.DroneSortie, 0, len(sorties))
+		for _, sortie := range sorties {
+			sortiesData = append(sortiesData, generated.DroneSortie{
+				Start:    plotResponse(sortie.Start),
+				End:      plotResponse(sortie.End),
+				Distance: sortie.Distance,
+			})
+		}
+		response.Sorties = &sortiesData
+	}
+
+	if params.ResumeX != nil || params.ResumeDistance != nil {
+		var resumption droneplan.Resumption
+		if params.ResumeX != nil {
+			resumption, err = planner.Resume(pad, droneplan.Position{X: *params.ResumeX, Y: *params.ResumeY})
+		} else {
+			resumption, err = planner.ResumeAfter(pad, *params.ResumeDistance)
+		}
+		if err != nil {
+			return
+		}
+
+		transitDistance := resumption.Transit[len(resumption.Transit)-1].Distance
+		response.Resume = &generated.DroneResumePlan{
+			Start:           plotResponse(resumption.Start),
+			Transit:         waypointsResponse(resumption.Transit),
+			TransitDistance: transitDistance,
+			Distance:        resumption.Distance,
+			TotalDistance:   transitDistance + resumption.Distance,
 		}
 	}
 
-	return c.JSON(http.StatusOK, generated.GetDronePlanResponse{
-		Distance: horizontalDistance + verticalDistance,
+	body, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	result = repository.DronePlan{
+		EstateId: id,
+		Version:  version,
+		Plan:     body,
+	}
+
+	// A plan that cannot be stored is computed again on the next request.
+	_ = s.Repository.CreateDronePlan(ctx, result)
+
+	return
+}
+
+// dronePlanVersion returns the version of the drone plan computed from the
+// trees, the no-fly zones, the terrain elevation, the max altitude and the
+// flight settings of the planner options and
+// the request parameters. The trees are represented by the digest of the
+// estate so that the version is known before loading them.
+func dronePlanVersion(opts droneplan.NewPlannerOptions, params generated.GetDronePlanByEstateIdParams) string {
+	zones := slices.Clone(opts.Zones)
+	slices.SortFunc(zones, func(a, b repository.NoFlyZone) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	key, _ := json.Marshal(struct {
+		Revision         int
+		TreesDigest      string
+		Zones            []repository.NoFlyZone
+		ElevationsDigest string
+		Clearance        int
+		LookAhead        int
+		MaxAltitude      int
+		Params           generated.GetDronePlanByEstateIdParams
+	}{
+		Revision:         dronePlanRevision,
+		TreesDigest:      opts.Estate.TreesDigest,
+		Zones:            zones,
+		ElevationsDigest: opts.Estate.ElevationsDigest,
+		Clearance:        opts.Clearance,
+		LookAhead:        opts.LookAhead,
+		MaxAltitude:      opts.MaxAltitude,
+		Params:           params,
+	})
+
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:])
+}
+
+// refusedDronePlan returns the refusal of a JSON drone plan crossing plots
+// above the max altitude when the parameters refuse such plans, or nil
+func refusedDronePlan(plan repository.DronePlan, params generated.GetDronePlanByEstateIdParams) (*generated.CeilingViolationResponse, error) {
+	if params.AltitudeCeiling == nil || *params.AltitudeCeiling != generated.Refuse {
+		return nil, nil
+	}
+
+	var response generated.GetDronePlanResponse
+	if err := json.Unmarshal(plan.Plan, &response); err != nil {
+		return nil, err
+	}
+	return ceilingRefusal(params, response.MaxAltitude, response.CeilingViolations), nil
+}
+
+// ceilingRefusal returns the refusal of the violations of the max altitude
+// when the parameters refuse them, or nil
+func ceilingRefusal(params generated.GetDronePlanByEstateIdParams, maxAltitude int, violations *[]generated.CeilingViolation) *generated.CeilingViolationResponse {
+	if params.AltitudeCeiling == nil || *params.AltitudeCeiling != generated.Refuse || violations == nil {
+		return nil
+	}
+
+	return &generated.CeilingViolationResponse{
+		Message:     fmt.Sprintf("Drone plan flies above the max altitude of %d meters over %d plots", maxAltitude, len(*violations)),
+		MaxAltitude: maxAltitude,
+		Violations:  *violations,
+	}
+}
+
+// missionFormat returns the mission file format requested by the format
+// parameter or the Accept header, or an empty format for a JSON drone plan
+func missionFormat(c echo.Context, params generated.GetDronePlanByEstateIdParams) generated.GetDronePlanByEstateIdParamsFormat {
+	if params.Format != nil {
+		return *params.Format
+	}
+
+	if strings.Contains(c.Request().Header.Get(echo.HeaderAccept), qgcPlanMIMEType) {
+		return generated.Qgc
+	}
+
+	return ""
+}
+
+// exportDronePlan responds with the drone route of the estate as a mission file
+func exportDronePlan(c echo.Context, id string, planner *droneplan.Planner, format generated.GetDronePlanByEstateIdParamsFormat, params generated.GetDronePlanByEstateIdParams) error {
+	if params.Latitude == nil || params.Longitude == nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Latitude and longitude are required to export a mission file",
+		})
+	}
+
+	if *params.Latitude < -90 || *params.Latitude > 90 || *params.Longitude < -180 || *params.Longitude > 180 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Latitude must be between -90 and 90 and longitude between -180 and 180",
+		})
+	}
+
+	origin := droneplan.Origin{
+		Latitude:  *params.Latitude,
+		Longitude: *params.Longitude,
+	}
+	items := droneplan.Mission(planner.Route(), origin)
+
+	var body bytes.Buffer
+	var err error
+	contentType, extension := qgcPlanMIMEType, "plan"
+	switch format {
+	case generated.MavlinkWpl:
+		contentType, extension = echo.MIMETextPlainCharsetUTF8, "waypoints"
+		err = droneplan.WriteMavlinkWPL(&body, items)
+	default:
+		err = droneplan.WriteQGCPlan(&body, items)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "estate-"+id+"."+extension))
+	return c.Blob(http.StatusOK, contentType, body.Bytes())
+}
+
+// Handler to get the drone route by estate id
+// GET  /estate/{id}/drone-plan/route
+func (s *Server) GetDronePlanRouteByEstateId(c echo.Context, id string, params generated.GetDronePlanRouteByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if params.Strategy != nil {
+		if !slices.Contains(droneplan.Strategies, droneplan.Strategy(*params.Strategy)) {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: "Strategy must be zigzag, spiral or tree-tour",
+			})
+		}
+		opts.Strategy = droneplan.Strategy(*params.Strategy)
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	route := planner.Route()
+	distance := 0
+	if len(route) > 0 {
+		distance = route[len(route)-1].Distance
+	}
+
+	return c.JSON(http.StatusOK, generated.GetDronePlanRouteResponse{
+		Distance:      distance,
+		NaiveDistance: naiveDistance(opts, planner.Sweep(), 0),
+		Sweep:         sweepResponse(planner.Sweep()),
+		Skipped:       skippedResponse(planner.Skipped()),
+		Waypoints:     waypointsResponse(route),
+	})
+}
+
+// Handler to validate a drone route authored outside the planner
+// POST  /estate/{id}/drone-plan/validate
+func (s *Server) ValidateDronePlanByEstateId(c echo.Context, id string, params generated.ValidateDronePlanByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	var req generated.ValidateRouteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Invalid Request Body",
+		})
+	}
+
+	if len(req.Waypoints) < 2 || len(req.Waypoints) > maxRouteWaypoints {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: fmt.Sprintf("A route must have between 2 and %d waypoints", maxRouteWaypoints),
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	waypoints := make([]droneplan.RoutePoint, 0, len(req.Waypoints))
+	for _, waypoint := range req.Waypoints {
+		waypoints = append(waypoints, droneplan.RoutePoint{
+			X:        waypoint.X,
+			Y:        waypoint.Y,
+			Altitude: waypoint.Altitude,
+		})
+	}
+	check := planner.CheckRoute(waypoints)
+
+	missed := make([]generated.PlotRun, 0, len(check.Missed))
+	for _, run := range check.Missed {
+		missed = append(missed, runResponse(run))
+	}
+
+	violations := make([]generated.RouteClearanceViolation, 0, len(check.Violations))
+	for _, violation := range check.Violations {
+		violations = append(violations, generated.RouteClearanceViolation{
+			Index:            violation.Index,
+			X:                violation.Sample.X,
+			Y:                violation.Sample.Y,
+			Altitude:         violation.Sample.Altitude,
+			RequiredAltitude: violation.Required,
+		})
+	}
+
+	segments := make([]generated.RouteSegmentViolation, 0, len(check.SegmentViolations))
+	for _, violation := range check.SegmentViolations {
+		segments = append(segments, generated.RouteSegmentViolation{
+			StartIndex:       violation.Index - 1,
+			EndIndex:         violation.Index,
+			X:                violation.Plot.X,
+			Y:                violation.Plot.Y,
+			Altitude:         violation.Altitude,
+			RequiredAltitude: violation.Required,
+		})
+	}
+
+	outOfBounds := make([]generated.IndexedRouteWaypoint, 0, len(check.OutOfBounds))
+	for _, index := range check.OutOfBounds {
+		outOfBounds = append(outOfBounds, generated.IndexedRouteWaypoint{
+			Index:    index,
+			X:        waypoints[index].X,
+			Y:        waypoints[index].Y,
+			Altitude: waypoints[index].Altitude,
+		})
+	}
+
+	return c.JSON(http.StatusOK, generated.RouteValidation{
+		Valid:               check.Valid,
+		Waypoints:           len(waypoints),
+		Coverage:            check.Coverage,
+		PlannedPlots:        check.PlannedPlots,
+		CoveredPlots:        check.CoveredPlots,
+		MissedPlots:         missed,
+		ClearanceViolations: violations,
+		SegmentViolations:   segments,
+		OutOfBounds:         outOfBounds,
+		Distance:            check.Distance,
+		PlannedDistance:     check.PlannedDistance,
+	})
+}
+
+// Handler to get the camera capture points along the drone route by estate id
+// GET  /estate/{id}/drone-plan/captures
+func (s *Server) GetDroneCapturePlanByEstateId(c echo.Context, id string, params generated.GetDroneCapturePlanByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	camera := droneplan.Camera{FieldOfView: 84, Overlap: 75}
+	if params.FieldOfView != nil {
+		camera.FieldOfView = *params.FieldOfView
+	}
+	if params.Overlap != nil {
+		camera.Overlap = *params.Overlap
+	}
+	if err := camera.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Field of view must be between 0 and 180 degrees and overlap at least 0 and below 100 percent",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	survey, err := planner.Survey(camera, maxCaptures)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	response := generated.GetDroneCapturePlanResponse{
+		Footprint:   survey.Footprint,
+		Spacing:     survey.Spacing,
+		SideOverlap: survey.SideOverlap,
+		ImageCount:  survey.Images,
+		Sweep:       sweepResponse(planner.Sweep()),
+	}
+	if survey.Captures != nil {
+		captures := make([]generated.CapturePoint, 0, len(survey.Captures))
+		for _, capture := range survey.Captures {
+			captures = append(captures, generated.CapturePoint{
+				X:        capture.X,
+				Y:        capture.Y,
+				Altitude: capture.Altitude,
+			})
+		}
+		response.Captures = &captures
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Handler to stream a simulated flight along the drone route by estate id
+// GET  /estate/{id}/drone-plan/simulate
+func (s *Server) GetDronePlanSimulationByEstateId(c echo.Context, id string, params generated.GetDronePlanSimulationByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	compression := 1.0
+	if params.Compression != nil {
+		if *params.Compression < 1 || *params.Compression > 10000 {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: "Compression must be between 1 and 10000",
+			})
+		}
+		compression = *params.Compression
+	}
+
+	err := s.registeredDrone(ctx, params.DroneId, droneSpecs{
+		BatteryRange: &params.BatteryRange,
+		Speed:        &params.Speed,
+		ClimbRate:    &params.ClimbRate,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if params.BatteryRange != nil && *params.BatteryRange < 1 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Battery range must be greater than 0",
+		})
+	}
+
+	performance := dronePerformance(params.Speed, params.ClimbRate, params.DescentRate, params.HoverOverhead, nil)
+	if err := performance.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Speed, climb rate and descent rate must be greater than 0 and hover overhead must not be negative",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	route := planner.Route()
+	batteryRange := 0
+	if len(route) > 0 {
+		batteryRange = route[len(route)-1].Distance
+	}
+	if params.BatteryRange != nil {
+		batteryRange = *params.BatteryRange
+	}
+
+	frames, err := droneplan.Simulate(route, performance, batteryRange)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// Every frame is sent when the drone reaches its waypoint, in compressed
+	// time since the stream started.
+	start := time.Now()
+	for _, frame := range frames {
+		wait := time.Until(start.Add(time.Duration(frame.Elapsed / compression * float64(time.Second))))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		if err := writeEvent(w, strconv.Itoa(frame.Index), "waypoint", simulationEventResponse(frame, batteryRange)); err != nil {
+			return nil
+		}
+	}
+
+	event := "complete"
+	if len(frames) < len(route) {
+		event = "depleted"
+	}
+	if len(frames) > 0 {
+		writeEvent(w, "", event, simulationEventResponse(frames[len(frames)-1], batteryRange))
+	}
+
+	return nil
+}
+
+// writeEvent writes a Server-Sent Event with JSON data and flushes it to the
+// client, the id line is omitted when the id is empty
+func writeEvent(w *echo.Response, id, event string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, body); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return nil
+}
+
+func simulationEventResponse(frame droneplan.Frame, batteryRange int) generated.SimulationEvent {
+	return generated.SimulationEvent{
+		Index: frame.Index,
+		Waypoint: generated.DroneWaypoint{
+			X:        frame.Waypoint.X,
+			Y:        frame.Waypoint.Y,
+			Altitude: frame.Waypoint.Altitude,
+			Distance: frame.Waypoint.Distance,
+		},
+		Distance:                frame.Waypoint.Distance,
+		Elapsed:                 frame.Elapsed,
+		RemainingBattery:        frame.Battery,
+		RemainingBatteryPercent: 100 * float64(frame.Battery) / float64(batteryRange),
+	}
+}
+
+// Handler to get the drone plans of a fleet sharing the estate
+// GET  /estate/{id}/drone-plan/fleet
+func (s *Server) GetDroneFleetPlanByEstateId(c echo.Context, id string, params generated.GetDroneFleetPlanByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	if params.Drones < 1 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Drones must be greater than 0",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	strips, err := planner.Partition(params.Drones)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	plans := make([]generated.DroneStripPlan, 0, len(strips))
+	for _, strip := range strips {
+		plans = append(plans, generated.DroneStripPlan{
+			From:     plotResponse(strip.From),
+			To:       plotResponse(strip.To),
+			Start:    plotResponse(strip.Start),
+			Rest:     plotResponse(strip.Plan.Rest),
+			Distance: strip.Plan.Distance,
+		})
+	}
+
+	return c.JSON(http.StatusOK, generated.GetDroneFleetPlanResponse{
+		Sweep: sweepResponse(planner.Sweep()),
+		Plans: plans,
+	})
+}
+
+// Handler to get an inspection flight over selected trees by estate id
+// GET  /estate/{id}/drone-plan/inspection
+func (s *Server) GetDroneInspectionPlanByEstateId(c echo.Context, id string, params generated.GetDroneInspectionPlanByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	if params.TreeId == nil && params.MinHeight == nil && params.Tallest == nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Select the trees to inspect with tree_id, min_height or tallest",
+		})
+	}
+
+	if (params.MinHeight != nil && *params.MinHeight < 0) || (params.Tallest != nil && *params.Tallest < 1) {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Min height must not be negative and tallest must be greater than 0",
+		})
+	}
+
+	pad := droneplan.Position{X: 1, Y: 1}
+	if params.LaunchX != nil {
+		pad.X = *params.LaunchX
+	}
+	if params.LaunchY != nil {
+		pad.Y = *params.LaunchY
+	}
+	if pad.X < 1 || pad.Y < 1 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Launch position must be greater than 0",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if pad.X > opts.Estate.Length || pad.Y > opts.Estate.Width {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Launch position must be inside the estate",
+		})
+	}
+
+	if err := flightProfile(&opts, params.Clearance, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	trees, err := inspectedTrees(opts.Trees, params)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	inspection, err := droneplan.NewPlanner(opts).Inspect(pad, trees)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	treesData := make([]generated.InspectedTree, 0, len(inspection.Trees))
+	for _, tree := range inspection.Trees {
+		treesData = append(treesData, inspectedTreeResponse(tree))
+	}
+
+	response := generated.GetDroneInspectionPlanResponse{
+		Distance:  inspection.Distance,
+		Trees:     treesData,
+		Waypoints: waypointsResponse(inspection.Route),
+	}
+	if len(inspection.Skipped) > 0 {
+		skipped := make([]generated.InspectedTree, 0, len(inspection.Skipped))
+		for _, tree := range inspection.Skipped {
+			skipped = append(skipped, inspectedTreeResponse(tree))
+		}
+		response.Skipped = &skipped
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// inspectedTrees selects the trees of the estate matching every filter of an
+// inspection request
+func inspectedTrees(trees []repository.EstateTree, params generated.GetDroneInspectionPlanByEstateIdParams) ([]repository.EstateTree, error) {
+	if params.TreeId != nil {
+		byId := make(map[string]repository.EstateTree, len(trees))
+		for _, tree := range trees {
+			byId[tree.Id] = tree
+		}
+
+		selected := make([]repository.EstateTree, 0, len(*params.TreeId))
+		seen := make(map[string]bool, len(*params.TreeId))
+		for _, treeId := range *params.TreeId {
+			tree, ok := byId[treeId]
+			if !ok {
+				return nil, fmt.Errorf("Tree id %s not found in the estate", treeId)
+			}
+			if !seen[treeId] {
+				seen[treeId] = true
+				selected = append(selected, tree)
+			}
+		}
+		trees = selected
+	}
+
+	if params.MinHeight != nil {
+		selected := make([]repository.EstateTree, 0, len(trees))
+		for _, tree := range trees {
+			if tree.Height >= *params.MinHeight {
+				selected = append(selected, tree)
+			}
+		}
+		trees = selected
+	}
+
+	if params.Tallest != nil && *params.Tallest < len(trees) {
+		tallest := slices.Clone(trees)
+		slices.SortStableFunc(tallest, func(a, b repository.EstateTree) int {
+			return b.Height - a.Height
+		})
+		trees = tallest[:*params.Tallest]
+	}
+
+	if len(trees) == 0 {
+		return nil, errors.New("No tree matches the selection")
+	}
+
+	return trees, nil
+}
+
+func inspectedTreeResponse(tree repository.EstateTree) generated.InspectedTree {
+	return generated.InspectedTree{
+		Id:     tree.Id,
+		X:      tree.X,
+		Y:      tree.Y,
+		Height: tree.Height,
+	}
+}
+
+// Handler to compare the flights of every strategy by estate id
+// GET  /estate/{id}/drone-plan/strategies
+func (s *Server) GetDroneStrategiesByEstateId(c echo.Context, id string, params generated.GetDroneStrategiesByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	err := s.registeredDrone(ctx, params.DroneId, droneSpecs{
+		Speed:     &params.Speed,
+		ClimbRate: &params.ClimbRate,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	performance := dronePerformance(params.Speed, params.ClimbRate, params.DescentRate, params.HoverOverhead, params.Power)
+	if err := performance.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Speed, climb rate and descent rate must be greater than 0, hover overhead and power must not be negative",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, params.LookAhead); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	var response generated.GetDroneStrategiesResponse
+	var shortest, fastest droneplan.Evaluation
+	for i, strategy := range droneplan.Strategies {
+		opts.Strategy = strategy
+		planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: err.Error(),
+			})
+		}
+
+		evaluation, err := planner.Evaluate(performance)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: err.Error(),
+			})
+		}
+
+		if i == 0 || evaluation.Distance < shortest.Distance {
+			shortest = evaluation
+		}
+		if i == 0 || evaluation.Estimate.Duration < fastest.Estimate.Duration {
+			fastest = evaluation
+		}
+		response.Strategies = append(response.Strategies, generated.StrategyEvaluation{
+			Strategy: generated.Strategy(evaluation.Strategy),
+			Sweep:    sweepResponse(evaluation.Sweep),
+			Distance: evaluation.Distance,
+			Climb:    evaluation.Climb,
+			Descent:  evaluation.Descent,
+			Estimate: estimateResponse(evaluation.Estimate),
+		})
+	}
+	response.Shortest = generated.Strategy(shortest.Strategy)
+	response.Fastest = generated.Strategy(fastest.Strategy)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Handler to get the spraying sorties over the trees by estate id
+// GET  /estate/{id}/drone-plan/spray
+func (s *Server) GetDroneSprayPlanByEstateId(c echo.Context, id string, params generated.GetDroneSprayPlanByEstateIdParams) error {
+	ctx := c.Request().Context()
+
+	err := s.registeredDrone(ctx, params.DroneId, droneSpecs{
+		TankCapacity: &params.TankCapacity,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	if params.TankCapacity == nil || *params.TankCapacity <= 0 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Tank capacity must be greater than 0",
+		})
+	}
+
+	tank := droneplan.Tank{Capacity: *params.TankCapacity, Refill: droneplan.Position{X: 1, Y: 1}}
+	if params.LaunchX != nil {
+		tank.Refill.X = *params.LaunchX
+	}
+	if params.LaunchY != nil {
+		tank.Refill.Y = *params.LaunchY
+	}
+	if tank.Refill.X < 1 || tank.Refill.Y < 1 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Launch position must be greater than 0",
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if tank.Refill.X > opts.Estate.Length || tank.Refill.Y > opts.Estate.Width {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Launch position must be inside the estate",
+		})
+	}
+
+	if err := flightProfile(&opts, params.Clearance, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	dosing := droneplan.Dosing{Base: opts.Estate.DoseBase, PerMeter: opts.Estate.DosePerMeter}
+	spraying, err := droneplan.NewPlanner(opts).Spray(opts.Trees, dosing, tank)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	sorties := make([]generated.SpraySortie, 0, len(spraying.Sorties))
+	for _, sortie := range spraying.Sorties {
+		trees := make([]generated.InspectedTree, 0, len(sortie.Trees))
+		for _, tree := range sortie.Trees {
+			trees = append(trees, inspectedTreeResponse(tree))
+		}
+		sorties = append(sorties, generated.SpraySortie{
+			Trees:    trees,
+			Litres:   sortie.Litres,
+			Distance: sortie.Distance,
+		})
+	}
+
+	response := generated.GetDroneSprayPlanResponse{
+		Dosing:   generated.SprayDosing{Base: dosing.Base, PerMeter: dosing.PerMeter},
+		Sorties:  sorties,
+		Litres:   spraying.Litres,
+		Distance: spraying.Distance,
+	}
+	if len(spraying.Skipped) > 0 {
+		skipped := make([]generated.InspectedTree, 0, len(spraying.Skipped))
+		for _, tree := range spraying.Skipped {
+			skipped = append(skipped, inspectedTreeResponse(tree))
+		}
+		response.Skipped = &skipped
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Handler to upload the telemetry log of a flight over an estate
+// POST  /estate/{id}/flights
+func (s *Server) CreateEstateIdFlight(c echo.Context, id string, params generated.CreateEstateIdFlightParams) error {
+	ctx := c.Request().Context()
+
+	samples, err := flightSamples(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	flight, err := s.Repository.CreateFlight(ctx, repository.Flight{
+		Id:       uuid.New().String(),
+		EstateId: id,
+		Samples:  samples,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, generated.CreateFlightResponse{
+		Id:     flight.Id,
+		Report: flightReportResponse(flight, planner.Compare(flight.Samples)),
+	})
+}
+
+// Handler to get the report comparing a flight with the drone plan
+// GET  /estate/{id}/flights/{flight_id}/report
+func (s *Server) GetEstateIdFlightReport(c echo.Context, id string, flightId string, params generated.GetEstateIdFlightReportParams) error {
+	ctx := c.Request().Context()
+
+	flight, err := s.Repository.GetFlightById(ctx, id, flightId)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, generated.ErrorResponse{
+			Message: "Flight id not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	opts, err := s.getPlannerOptions(ctx, id)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	if err := flightProfile(&opts, params.Clearance, nil); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	planner, _, err := dronePlanner(opts, params.StartCorner, params.Orientation)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, flightReportResponse(flight, planner.Compare(flight.Samples)))
+}
+
+// Handler to save a drone plan of an estate as a mission
+// POST  /estate/{id}/missions
+func (s *Server) CreateEstateIdMission(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	var req generated.CreateMissionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Invalid Request Body",
+		})
+	}
+
+	var parameters generated.DronePlanParameters
+	if req.Parameters != nil {
+		parameters = *req.Parameters
+	}
+	if parameters.DroneId != nil && *parameters.DroneId != req.DroneId {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Parameters drone_id must be the drone of the mission",
+		})
+	}
+
+	if _, err := s.Repository.GetDroneById(ctx, req.DroneId); err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: "Drone id not found",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	params := dronePlanParams(parameters)
+	if err := s.dronePlanSpecs(ctx, &params); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+	if err := validateDronePlanParams(params); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	plan, estate, err := s.dronePlan(ctx, id, params)
+	if err != nil {
+		return estateError(c, err)
+	}
+
+	refused, err := refusedDronePlan(plan, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+	if refused != nil {
+		return c.JSON(http.StatusUnprocessableEntity, refused)
+	}
+
+	parametersData, err := json.Marshal(parameters)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	mission, err := s.Repository.CreateMission(ctx, repository.Mission{
+		Id:          uuid.New().String(),
+		EstateId:    id,
+		DroneId:     req.DroneId,
+		Status:      repository.MissionScheduled,
+		Parameters:  parametersData,
+		TreesDigest: estate.TreesDigest,
+		Version:     plan.Version,
+		Plan:        plan.Plan,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return missionResponse(c, http.StatusCreated, mission)
+}
+
+// Handler to list the missions of an estate
+// GET  /estate/{id}/missions
+func (s *Server) GetEstateIdMissions(c echo.Context, id string, params generated.GetEstateIdMissionsParams) error {
+	ctx := c.Request().Context()
+
+	var status repository.MissionStatus
+	if params.Status != nil {
+		status = repository.MissionStatus(*params.Status)
+		if !validMissionStatus(status) {
+			return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+				Message: "Status must be scheduled, in_flight, completed or aborted",
+			})
+		}
+	}
+
+	result, err := s.Repository.GetMissionsByEstateId(ctx, id, status)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	missions := make([]generated.Mission, 0, len(result))
+	for _, mission := range result {
+		missionData, err := missionData(mission)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, generated.ErrorResponse{
+				Message: err.Error(),
+			})
+		}
+		missions = append(missions, missionData)
+	}
+
+	return c.JSON(http.StatusOK, generated.GetMissionsResponse{
+		Missions: missions,
+	})
+}
+
+// Handler to get a mission of an estate
+// GET  /estate/{id}/missions/{mission_id}
+func (s *Server) GetEstateIdMission(c echo.Context, id string, missionId string) error {
+	ctx := c.Request().Context()
+
+	mission, err := s.Repository.GetMissionById(ctx, id, missionId)
+	if err != nil {
+		return missionError(c, err)
+	}
+
+	return missionResponse(c, http.StatusOK, mission)
+}
+
+// missionTransitions lists the statuses a mission can move to from its status
+var missionTransitions = map[repository.MissionStatus][]repository.MissionStatus{
+	repository.MissionScheduled: {repository.MissionInFlight, repository.MissionAborted},
+	repository.MissionInFlight:  {repository.MissionCompleted, repository.MissionAborted},
+}
+
+// Handler to move a mission of an estate to another status
+// POST  /estate/{id}/missions/{mission_id}/transitions
+func (s *Server) CreateEstateIdMissionTransition(c echo.Context, id string, missionId string) error {
+	ctx := c.Request().Context()
+
+	var req generated.MissionTransitionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Invalid Request Body",
+		})
+	}
+
+	to := repository.MissionStatus(req.Status)
+	if !validMissionStatus(to) {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Status must be scheduled, in_flight, completed or aborted",
+		})
+	}
+
+	mission, err := s.Repository.GetMissionById(ctx, id, missionId)
+	if err != nil {
+		return missionError(c, err)
+	}
+
+	if !slices.Contains(missionTransitions[mission.Status], to) {
+		return c.JSON(http.StatusConflict, generated.ErrorResponse{
+			Message: fmt.Sprintf("Mission cannot move from %s to %s", mission.Status, to),
+		})
+	}
+
+	// The status is only updated if no other transition happened meanwhile.
+	mission, err = s.Repository.UpdateMissionStatus(ctx, id, missionId, mission.Status, to)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusConflict, generated.ErrorResponse{
+			Message: "Mission status changed meanwhile, try again",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return missionResponse(c, http.StatusOK, mission)
+}
+
+// Handler to register a drone
+// POST  /drones
+func (s *Server) CreateDrone(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req generated.CreateDroneRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Invalid Request Body",
+		})
+	}
+
+	if strings.TrimSpace(req.Model) == "" {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Model is required",
+		})
+	}
+
+	if req.MaxRange < 1 || req.CruiseSpeed <= 0 || req.ClimbRate <= 0 || req.PayloadCapacity < 0 {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: "Max range, cruise speed and climb rate must be greater than 0 and payload capacity must not be negative",
+		})
+	}
+
+	drone, err := s.Repository.CreateDrone(ctx, repository.Drone{
+		Id:              uuid.New().String(),
+		Model:           req.Model,
+		MaxRange:        req.MaxRange,
+		CruiseSpeed:     req.CruiseSpeed,
+		ClimbRate:       req.ClimbRate,
+		PayloadCapacity: req.PayloadCapacity,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, droneData(drone))
+}
+
+// Handler to list the registered drones
+// GET  /drones
+func (s *Server) GetDrones(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	result, err := s.Repository.GetDrones(ctx)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	drones := make([]generated.Drone, 0, len(result))
+	for _, drone := range result {
+		drones = append(drones, droneData(drone))
+	}
+
+	return c.JSON(http.StatusOK, generated.GetDronesResponse{
+		Drones: drones,
+	})
+}
+
+// Handler to get a registered drone
+// GET  /drones/{id}
+func (s *Server) GetDroneById(c echo.Context, id string) error {
+	ctx := c.Request().Context()
+
+	drone, err := s.Repository.GetDroneById(ctx, id)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, generated.ErrorResponse{
+			Message: "Drone id not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, droneData(drone))
+}
+
+func droneData(drone repository.Drone) generated.Drone {
+	return generated.Drone{
+		Id:              drone.Id,
+		Model:           drone.Model,
+		MaxRange:        drone.MaxRange,
+		CruiseSpeed:     drone.CruiseSpeed,
+		ClimbRate:       drone.ClimbRate,
+		PayloadCapacity: drone.PayloadCapacity,
+		CreatedAt:       drone.CreatedAt,
+	}
+}
+
+// droneSpecs points to the parameters of a request the specs of a registered
+// drone give, nil for the ones the endpoint does not have
+type droneSpecs struct {
+	BatteryRange **int
+	Speed        **float64
+	ClimbRate    **float64
+	TankCapacity **float64
+}
+
+// registeredDrone sets the parameters of a request referencing a registered
+// drone to its specs, the request must leave them out
+func (s *Server) registeredDrone(ctx context.Context, droneId *string, specs droneSpecs) error {
+	if droneId == nil {
+		return nil
+	}
+
+	if (specs.BatteryRange != nil && *specs.BatteryRange != nil) ||
+		(specs.Speed != nil && *specs.Speed != nil) ||
+		(specs.ClimbRate != nil && *specs.ClimbRate != nil) ||
+		(specs.TankCapacity != nil && *specs.TankCapacity != nil) {
+		return errors.New("Battery range, speed, climb rate and tank capacity come from the drone, leave them out")
+	}
+
+	drone, err := s.Repository.GetDroneById(ctx, *droneId)
+	if err == sql.ErrNoRows {
+		return errors.New("Drone id not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if specs.BatteryRange != nil {
+		*specs.BatteryRange = &drone.MaxRange
+	}
+	if specs.Speed != nil {
+		*specs.Speed = &drone.CruiseSpeed
+	}
+	if specs.ClimbRate != nil {
+		*specs.ClimbRate = &drone.ClimbRate
+	}
+	if specs.TankCapacity != nil {
+		*specs.TankCapacity = &drone.PayloadCapacity
+	}
+	return nil
+}
+
+// dronePlanSpecs sets the drone plan parameters referencing a registered drone
+// to its specs
+func (s *Server) dronePlanSpecs(ctx context.Context, params *generated.GetDronePlanByEstateIdParams) error {
+	return s.registeredDrone(ctx, params.DroneId, droneSpecs{
+		BatteryRange: &params.BatteryRange,
+		Speed:        &params.Speed,
+		ClimbRate:    &params.ClimbRate,
+	})
+}
+
+// dronePlanParams returns the drone plan query parameters of the drone plan
+// parameters of a mission
+func dronePlanParams(parameters generated.DronePlanParameters) generated.GetDronePlanByEstateIdParams {
+	return generated.GetDronePlanByEstateIdParams{
+		MaxDistance:     parameters.MaxDistance,
+		Strategy:        parameters.Strategy,
+		StartCorner:     parameters.StartCorner,
+		Orientation:     parameters.Orientation,
+		Clearance:       parameters.Clearance,
+		LookAhead:       parameters.LookAhead,
+		Speed:           parameters.Speed,
+		ClimbRate:       parameters.ClimbRate,
+		DescentRate:     parameters.DescentRate,
+		HoverOverhead:   parameters.HoverOverhead,
+		Power:           parameters.Power,
+		BatteryRange:    parameters.BatteryRange,
+		LaunchX:         parameters.LaunchX,
+		LaunchY:         parameters.LaunchY,
+		ResumeX:         parameters.ResumeX,
+		ResumeY:         parameters.ResumeY,
+		ResumeDistance:  parameters.ResumeDistance,
+		AltitudeCeiling: parameters.AltitudeCeiling,
+		DroneId:         parameters.DroneId,
+	}
+}
+
+func validMissionStatus(status repository.MissionStatus) bool {
+	switch status {
+	case repository.MissionScheduled, repository.MissionInFlight, repository.MissionCompleted, repository.MissionAborted:
+		return true
+	}
+	return false
+}
+
+// missionData converts a mission with its stored parameters and plan
+func missionData(mission repository.Mission) (generated.Mission, error) {
+	data := generated.Mission{
+		Id:          mission.Id,
+		DroneId:     mission.DroneId,
+		Status:      generated.MissionStatus(mission.Status),
+		TreesDigest: mission.TreesDigest,
+		Version:     mission.Version,
+		CreatedAt:   mission.CreatedAt,
+		StartedAt:   mission.StartedAt,
+		EndedAt:     mission.EndedAt,
+	}
+
+	if err := json.Unmarshal(mission.Parameters, &data.Parameters); err != nil {
+		return data, err
+	}
+	if err := json.Unmarshal(mission.Plan, &data.Plan); err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+func missionResponse(c echo.Context, status int, mission repository.Mission) error {
+	data, err := missionData(mission)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, generated.ErrorResponse{
+			Message: err.Error(),
+		})
+	}
+
+	return c.JSON(status, data)
+}
+
+func missionError(c echo.Context, err error) error {
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, generated.ErrorResponse{
+			Message: "Mission id not found",
+		})
+	}
+
+	return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+		Message: err.Error(),
+	})
+}
+
+// flightSamples reads the telemetry log of a flight from a CSV or JSON request
+// body, sorted by time
+func flightSamples(c echo.Context) ([]repository.FlightSample, error) {
+	var samples []repository.FlightSample
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		var err error
+		samples, err = readFlightCSV(c.Request().Body)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var req generated.CreateFlightRequest
+		if err := c.Bind(&req); err != nil {
+			return nil, errors.New("Invalid Request Body")
+		}
+
+		for _, sample := range req.Samples {
+			samples = append(samples, repository.FlightSample{
+				Time:     sample.Timestamp,
+				X:        sample.X,
+				Y:        sample.Y,
+				Altitude: sample.Altitude,
+			})
+		}
+	}
+
+	if len(samples) < 2 || len(samples) > maxFlightSamples {
+		return nil, fmt.Errorf("A flight log must have between 2 and %d samples", maxFlightSamples)
+	}
+
+	slices.SortStableFunc(samples, func(a, b repository.FlightSample) int {
+		return a.Time.Compare(b.Time)
+	})
+	return samples, nil
+}
+
+// readFlightCSV reads the samples of a CSV flight log, the header row naming
+// the timestamp, x, y and altitude columns
+func readFlightCSV(body io.Reader) ([]repository.FlightSample, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("Invalid CSV flight log, a header row is required")
+	}
+
+	columns := map[string]int{"timestamp": -1, "x": -1, "y": -1, "altitude": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for name, i := range columns {
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid CSV flight log, the %s column is missing", name)
+		}
+	}
+
+	var samples []repository.FlightSample
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV flight log: %w", err)
+		}
+		if len(samples) == maxFlightSamples {
+			return nil, fmt.Errorf("A flight log must have between 2 and %d samples", maxFlightSamples)
+		}
+
+		var sample repository.FlightSample
+		sample.Time, err = time.Parse(time.RFC3339Nano, record[columns["timestamp"]])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid timestamp on line %d of the flight log", line)
+		}
+		for _, field := range []struct {
+			name  string
+			value *float64
+		}{
+			{"x", &sample.X},
+			{"y", &sample.Y},
+			{"altitude", &sample.Altitude},
+		} {
+			*field.value, err = strconv.ParseFloat(record[columns[field.name]], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %s on line %d of the flight log", field.name, line)
+			}
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+// readElevationCSV reads the plots of a CSV elevation grid, the header row
+// naming the x, y and elevation columns
+func readElevationCSV(body io.Reader) ([]repository.PlotElevation, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("Invalid CSV elevation grid, a header row is required")
+	}
+
+	columns := map[string]int{"x": -1, "y": -1, "elevation": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	for name, i := range columns {
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid CSV elevation grid, the %s column is missing", name)
+		}
+	}
+
+	var plots []repository.PlotElevation
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CSV elevation grid: %w", err)
+		}
+		if len(plots) == maxPlotElevations {
+			return nil, fmt.Errorf("An elevation grid must have at most %d plots", maxPlotElevations)
+		}
+
+		var plot repository.PlotElevation
+		for _, field := range []struct {
+			name  string
+			value *int
+		}{
+			{"x", &plot.X},
+			{"y", &plot.Y},
+		} {
+			*field.value, err = strconv.Atoi(record[columns[field.name]])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %s on line %d of the elevation grid", field.name, line)
+			}
+		}
+		plot.Elevation, err = parseElevation(record[columns["elevation"]])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid elevation on line %d of the elevation grid", line)
+		}
+		plots = append(plots, plot)
+	}
+
+	return plots, nil
+}
+
+// readElevationGrid reads the plots of an ESRI ASCII elevation grid. Its cells
+// are plots, its lower left corner being xllcorner and yllcorner meters from
+// the south-west corner of the estate, and its rows run from north to south.
+func readElevationGrid(body io.Reader) ([]repository.PlotElevation, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, errors.New("Invalid Request Body")
+	}
+	tokens := strings.Fields(string(data))
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	header := map[string]string{}
+	for len(tokens) >= 2 {
+		key := strings.ToLower(tokens[0])
+		if _, err := strconv.ParseFloat(key, 64); err == nil {
+			break
+		}
+		header[key] = tokens[1]
+		tokens = tokens[2:]
+	}
+
+	var ncols, nrows, columnOffset, rowOffset int
+	for _, field := range []struct {
+		name   string
+		value  *int
+		meters bool
+	}{
+		{"ncols", &ncols, false},
+		{"nrows", &nrows, false},
+		{"xllcorner", &columnOffset, true},
+		{"yllcorner", &rowOffset, true},
+	} {
+		value, ok := header[field.name]
+		if !ok {
+			return nil, fmt.Errorf("Invalid ESRI ASCII elevation grid, %s is missing", field.name)
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if field.meters {
+			number /= droneplan.PlotSize
+		}
+		if err != nil || number != float64(int(number)) || number < 0 {
+			return nil, fmt.Errorf("Invalid %s of the elevation grid", field.name)
+		}
+		*field.value = int(number)
+	}
+	if cellsize, err := strconv.ParseFloat(header["cellsize"], 64); err != nil || cellsize != droneplan.PlotSize {
+		return nil, fmt.Errorf("Invalid cellsize of the elevation grid, it must be %d like the plots", droneplan.PlotSize)
+	}
+	if ncols*nrows > maxPlotElevations {
+		return nil, fmt.Errorf("An elevation grid must have at most %d plots", maxPlotElevations)
+	}
+	if len(tokens) != ncols*nrows {
+		return nil, fmt.Errorf("Invalid ESRI ASCII elevation grid, %d cells are expected but there are %d", ncols*nrows, len(tokens))
+	}
+
+	nodata, hasNodata := header["nodata_value"]
+	var nodataValue float64
+	if hasNodata {
+		if nodataValue, err = strconv.ParseFloat(nodata, 64); err != nil {
+			return nil, errors.New("Invalid NODATA_value of the elevation grid")
+		}
+	}
+
+	plots := make([]repository.PlotElevation, 0, len(tokens))
+	for i, token := range tokens {
+		row, column := i/ncols, i%ncols
+		value, err := parseElevation(token)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid elevation on row %d column %d of the elevation grid", row+1, column+1)
+		}
+		if hasNodata && value == nodataValue {
+			continue
+		}
+		plots = append(plots, repository.PlotElevation{
+			X:         columnOffset + column + 1,
+			Y:         rowOffset + nrows - row,
+			Elevation: value,
+		})
+	}
+
+	return plots, nil
+}
+
+// parseElevation parses a finite elevation in meters
+func parseElevation(value string) (float64, error) {
+	elevation, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(elevation) || math.IsInf(elevation, 0) {
+		return 0, errors.New("invalid elevation")
+	}
+	return elevation, nil
+}
+
+func flightReportResponse(flight repository.Flight, report droneplan.Report) generated.FlightReport {
+	skipped := make([]generated.PlotRun, 0, len(report.Missed))
+	for _, run := range report.Missed {
+		skipped = append(skipped, runResponse(run))
+	}
+
+	violations := make([]generated.AltitudeViolation, 0, len(report.Violations))
+	for _, violation := range report.Violations {
+		violations = append(violations, generated.AltitudeViolation{
+			Timestamp:        violation.Sample.Time,
+			X:                violation.Sample.X,
+			Y:                violation.Sample.Y,
+			Altitude:         violation.Sample.Altitude,
+			RequiredAltitude: violation.Required,
+		})
+	}
+
+	segments := make([]generated.FlightSegmentViolation, 0, len(report.SegmentViolations))
+	for _, violation := range report.SegmentViolations {
+		segments = append(segments, generated.FlightSegmentViolation{
+			StartTimestamp:   flight.Samples[violation.Index-1].Time,
+			EndTimestamp:     flight.Samples[violation.Index].Time,
+			X:                violation.Plot.X,
+			Y:                violation.Plot.Y,
+			Altitude:         violation.Altitude,
+			RequiredAltitude: violation.Required,
+		})
+	}
+
+	return generated.FlightReport{
+		FlightId:           flight.Id,
+		Samples:            len(flight.Samples),
+		Coverage:           report.Coverage,
+		PlannedPlots:       report.PlannedPlots,
+		CoveredPlots:       report.CoveredPlots,
+		SkippedPlots:       skipped,
+		AltitudeViolations: violations,
+		SegmentViolations:  segments,
+		Distance:           report.Distance,
+		PlannedDistance:    report.PlannedDistance,
+		ExtraDistance:      report.Distance - float64(report.PlannedDistance),
+	}
+}
+
+// dronePlanner builds the drone planner of the estate sweeping from the start
+// corner along the orientation requested. When either of them is auto, every
+// matching sweep is evaluated and the planner uses the shortest one.
+func dronePlanner(opts droneplan.NewPlannerOptions, corner *generated.StartCorner, orientation *generated.Orientation) (*droneplan.Planner, []droneplan.Alternative, error) {
+	corners := []droneplan.Corner{droneplan.SouthWest}
+	if corner != nil {
+		switch *corner {
+		case generated.StartCornerAuto:
+			corners = droneplan.Corners
+		case generated.StartCornerSouthWest, generated.StartCornerSouthEast, generated.StartCornerNorthWest, generated.StartCornerNorthEast:
+			corners = []droneplan.Corner{droneplan.Corner(*corner)}
+		default:
+			return nil, nil, errors.New("Start corner must be south-west, south-east, north-west, north-east or auto")
+		}
+	}
+
+	orientations := []droneplan.Orientation{droneplan.AlongX}
+	if orientation != nil {
+		switch *orientation {
+		case generated.OrientationAuto:
+			orientations = droneplan.Orientations
+		case generated.OrientationX, generated.OrientationY:
+			orientations = []droneplan.Orientation{droneplan.Orientation(*orientation)}
+		default:
+			return nil, nil, errors.New("Orientation must be x, y or auto")
+		}
+	}
+
+	opts.Sweep = droneplan.Sweep{Corner: corners[0], Orientation: orientations[0]}
+
+	var alternatives []droneplan.Alternative
+	if len(corners)*len(orientations) > 1 {
+		alternatives = droneplan.Alternatives(opts, corners, orientations)
+		opts.Sweep = droneplan.Shortest(alternatives)
+	}
+
+	return droneplan.NewPlanner(opts), alternatives, nil
+}
+
+// flightProfile overrides the clearance and look-ahead of the planner options
+// with the ones requested
+func flightProfile(opts *droneplan.NewPlannerOptions, clearance, lookAhead *int) error {
+	if clearance != nil {
+		if *clearance < 1 || *clearance > droneplan.MaxClearance {
+			return fmt.Errorf("Clearance must be between 1 and %d", droneplan.MaxClearance)
+		}
+		opts.Clearance = *clearance
+	}
+
+	if lookAhead != nil {
+		if *lookAhead < 0 || *lookAhead > droneplan.MaxLookAhead {
+			return fmt.Errorf("Look-ahead must be between 0 and %d", droneplan.MaxLookAhead)
+		}
+		opts.LookAhead = *lookAhead
+	}
+
+	if opts.MaxAltitude > 0 && opts.Clearance > opts.MaxAltitude {
+		return fmt.Errorf("Clearance must not be above the max altitude of %d meters", opts.MaxAltitude)
+	}
+
+	return nil
+}
+
+// dronePerformance returns the default drone performance overridden by the
+// values requested
+func dronePerformance(speed, climbRate, descentRate, hoverOverhead, power *float64) droneplan.Performance {
+	performance := droneplan.DefaultPerformance
+	if speed != nil {
+		performance.Speed = *speed
+	}
+	if climbRate != nil {
+		performance.ClimbRate = *climbRate
+	}
+	if descentRate != nil {
+		performance.DescentRate = *descentRate
+	}
+	if hoverOverhead != nil {
+		performance.HoverTime = *hoverOverhead
+	}
+	if power != nil {
+		performance.Power = *power
+	}
+	return performance
+}
+
+func estimateResponse(estimate droneplan.Estimate) generated.FlightEstimate {
+	return generated.FlightEstimate{
+		Duration:           estimate.Duration,
+		HorizontalDuration: estimate.Horizontal,
+		VerticalDuration:   estimate.Vertical,
+		HoverDuration:      estimate.Hover,
+		Energy:             estimate.Energy,
+	}
+}
+
+// naiveDistance returns the distance of the sweep following the canopy plot
+// by plot, omitted when the drone does not look ahead
+func naiveDistance(opts droneplan.NewPlannerOptions, sweep droneplan.Sweep, maxDistance int) *int {
+	if opts.LookAhead == 0 {
+		return nil
+	}
+
+	opts.Sweep, opts.LookAhead = sweep, 0
+	distance := droneplan.NewPlanner(opts).Compute(maxDistance).Distance
+	return &distance
+}
+
+func waypointsResponse(route []droneplan.Waypoint) []generated.DroneWaypoint {
+	waypoints := make([]generated.DroneWaypoint, 0, len(route))
+	for _, waypoint := range route {
+		waypoints = append(waypoints, generated.DroneWaypoint{
+			X:        waypoint.X,
+			Y:        waypoint.Y,
+			Altitude: waypoint.Altitude,
+			Distance: waypoint.Distance,
+		})
+	}
+	return waypoints
+}
+
+func plotResponse(plot droneplan.Position) generated.PlotPosition {
+	return generated.PlotPosition{
+		X: plot.X,
+		Y: plot.Y,
+	}
+}
+
+func runResponse(run droneplan.Run) generated.PlotRun {
+	return generated.PlotRun{
+		Start: plotResponse(run.Start),
+		End:   plotResponse(run.End),
+	}
+}
+
+// skippedResponse lists the runs of plots left out of a drone plan, omitted when none are
+func skippedResponse(runs []droneplan.Run) *[]generated.PlotRun {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	skipped := make([]generated.PlotRun, 0, len(runs))
+	for _, run := range runs {
+		skipped = append(skipped, runResponse(run))
+	}
+	return &skipped
+}
+
+func ceilingViolationsResponse(violations []droneplan.CeilingViolation) *[]generated.CeilingViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	response := make([]generated.CeilingViolation, 0, len(violations))
+	for _, violation := range violations {
+		response = append(response, generated.CeilingViolation{
+			X:         violation.Plot.X,
+			Y:         violation.Plot.Y,
+			Height:    violation.Height,
+			Elevation: violation.Elevation,
+			Altitude:  violation.Altitude,
+		})
+	}
+	return &response
+}
+
+func sweepResponse(sweep droneplan.Sweep) generated.DroneSweep {
+	return generated.DroneSweep{
+		StartCorner: string(sweep.Corner),
+		Orientation: string(sweep.Orientation),
+	}
+}
+
+// getPlannerOptions loads an estate with all of its trees, no-fly zones and
+// terrain elevation, and its flight settings, the data drone plans are
+// computed from
+func (s *Server) getPlannerOptions(ctx context.Context, id string) (opts droneplan.NewPlannerOptions, err error) {
+	opts, err = s.getEstateOptions(ctx, id)
+	if err != nil {
+		return
+	}
+
+	opts.Trees, err = s.Repository.GetTreesByEstateId(ctx, id)
+	if err != nil {
+		return
+	}
+
+	opts.Elevations, err = s.Repository.GetPlotElevationsByEstateId(ctx, id)
+	return
+}
+
+// getEstateOptions loads an estate with its no-fly zones and flight settings,
+// the planner options without the trees and the terrain elevation
+func (s *Server) getEstateOptions(ctx context.Context, id string) (opts droneplan.NewPlannerOptions, err error) {
+	opts.Estate, err = s.Repository.GetEstateById(ctx, id)
+	if err != nil {
+		return
+	}
+
+	opts.Zones, err = s.Repository.GetNoFlyZonesByEstateId(ctx, id)
+	if err != nil {
+		return
+	}
+
+	opts.Clearance = opts.Estate.Clearance
+	opts.LookAhead = opts.Estate.LookAhead
+	opts.MaxAltitude = opts.Estate.MaxAltitude
+	return
+}
+
+// estateError responds with 404 when the estate does not exist and 400 otherwise
+func estateError(c echo.Context, err error) error {
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, generated.ErrorResponse{
+			Message: "Estate id not found",
+		})
+	}
+
+	return c.JSON(http.StatusBadRequest, generated.ErrorResponse{
+		Message: err.Error(),
 	})
 }
@@ -2,18 +2,28 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 func (r *Repository) CreateEstate(ctx context.Context, input Estate) (result Estate, err error) {
 	var id string
 	err = r.Db.QueryRowContext(ctx, `
-		INSERT INTO estates (id, width, length)
-		VALUES ($1, $2, $3)
+		INSERT INTO estates (id, width, length, clearance, look_ahead, dose_base, dose_per_meter, max_altitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		returning id;
 	`,
 		input.Id,
 		input.Width,
 		input.Length,
+		input.Clearance,
+		input.LookAhead,
+		input.DoseBase,
+		input.DosePerMeter,
+		input.MaxAltitude,
 	).Scan(&id)
 	if err != nil {
 		return
@@ -26,8 +36,8 @@ func (r *Repository) CreateEstate(ctx context.Context, input Estate) (result Est
 
 func (r *Repository) CreateEstateTree(ctx context.Context, input EstateTree) (result EstateTree, err error) {
 	err = r.Db.QueryRowContext(ctx, `
-		INSERT INTO trees (id, estate_id, x, y, height)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO trees (id, estate_id, x, y, height, canopy_diameter)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		returning id;
 	`,
 		input.Id,
@@ -35,6 +45,7 @@ func (r *Repository) CreateEstateTree(ctx context.Context, input EstateTree) (re
 		input.X,
 		input.Y,
 		input.Height,
+		input.CanopyDiameter,
 	).Scan(&result.Id)
 	if err != nil {
 		return
@@ -68,11 +79,18 @@ func (r *Repository) GetStatsByEstateId(ctx context.Context, id string) (result
 
 func (r *Repository) GetEstateById(ctx context.Context, id string) (result Estate, err error) {
 	err = r.Db.QueryRowContext(ctx, `
-		SELECT id, width, length FROM estates WHERE id = $1;
+		SELECT id, width, length, clearance, look_ahead, dose_base, dose_per_meter, max_altitude, trees_digest::text, elevations_digest::text FROM estates WHERE id = $1;
 	`, id).Scan(
 		&result.Id,
 		&result.Width,
 		&result.Length,
+		&result.Clearance,
+		&result.LookAhead,
+		&result.DoseBase,
+		&result.DosePerMeter,
+		&result.MaxAltitude,
+		&result.TreesDigest,
+		&result.ElevationsDigest,
 	)
 	if err != nil {
 		return
@@ -80,9 +98,23 @@ func (r *Repository) GetEstateById(ctx context.Context, id string) (result Estat
 	return
 }
 
+// UpdateEstateDosing sets the spraying dosing of the estate, sql.ErrNoRows is
+// returned when the estate does not exist.
+func (r *Repository) UpdateEstateDosing(ctx context.Context, input Estate) (err error) {
+	var id string
+	return r.Db.QueryRowContext(ctx, `
+		UPDATE estates SET dose_base = $2, dose_per_meter = $3 WHERE id = $1
+		returning id;
+	`,
+		input.Id,
+		input.DoseBase,
+		input.DosePerMeter,
+	).Scan(&id)
+}
+
 func (r *Repository) GetTreesByEstateId(ctx context.Context, id string) (result []EstateTree, err error) {
 	rows, err := r.Db.QueryContext(ctx, `
-        SELECT id, estate_id, x, y, height FROM trees WHERE estate_id = $1;
+        SELECT id, estate_id, x, y, height, canopy_diameter FROM trees WHERE estate_id = $1;
     `, id)
 	if err != nil {
 		return
@@ -97,6 +129,7 @@ func (r *Repository) GetTreesByEstateId(ctx context.Context, id string) (result
 			&tree.X,
 			&tree.Y,
 			&tree.Height,
+			&tree.CanopyDiameter,
 		)
 		if err != nil {
 			return
@@ -106,3 +139,408 @@ func (r *Repository) GetTreesByEstateId(ctx context.Context, id string) (result
 
 	return
 }
+
+func (r *Repository) CreateNoFlyZone(ctx context.Context, input NoFlyZone) (result NoFlyZone, err error) {
+	err = r.Db.QueryRowContext(ctx, `
+		INSERT INTO no_fly_zones (id, estate_id, name, area)
+		VALUES ($1, $2, $3, $4::polygon)
+		returning id;
+	`,
+		input.Id,
+		input.EstateId,
+		input.Name,
+		formatPolygon(input.Vertices),
+	).Scan(&result.Id)
+	if err != nil {
+		return
+	}
+
+	result = input
+
+	return
+}
+
+func (r *Repository) GetNoFlyZonesByEstateId(ctx context.Context, id string) (result []NoFlyZone, err error) {
+	rows, err := r.Db.QueryContext(ctx, `
+        SELECT id, estate_id, name, area::text FROM no_fly_zones WHERE estate_id = $1;
+    `, id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var zone NoFlyZone
+		var area string
+		err = rows.Scan(
+			&zone.Id,
+			&zone.EstateId,
+			&zone.Name,
+			&area,
+		)
+		if err != nil {
+			return
+		}
+
+		zone.Vertices, err = parsePolygon(area)
+		if err != nil {
+			return
+		}
+		result = append(result, zone)
+	}
+
+	return
+}
+
+func (r *Repository) ReplacePlotElevations(ctx context.Context, estateId string, input []PlotElevation) (err error) {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM plot_elevations WHERE estate_id = $1;
+	`, estateId)
+	if err != nil {
+		return
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("plot_elevations", "estate_id", "x", "y", "elevation"))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	for _, plot := range input {
+		_, err = stmt.ExecContext(ctx, estateId, plot.X, plot.Y, plot.Elevation)
+		if err != nil {
+			return
+		}
+	}
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetPlotElevationsByEstateId(ctx context.Context, id string) (result []PlotElevation, err error) {
+	rows, err := r.Db.QueryContext(ctx, `
+        SELECT x, y, elevation FROM plot_elevations WHERE estate_id = $1 ORDER BY y, x;
+    `, id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var plot PlotElevation
+		err = rows.Scan(
+			&plot.X,
+			&plot.Y,
+			&plot.Elevation,
+		)
+		if err != nil {
+			return
+		}
+		result = append(result, plot)
+	}
+
+	return
+}
+
+func (r *Repository) GetDronePlan(ctx context.Context, estateId, version string) (result DronePlan, err error) {
+	err = r.Db.QueryRowContext(ctx, `
+		SELECT estate_id, version, plan FROM drone_plans WHERE estate_id = $1 AND version = $2;
+	`, estateId, version).Scan(
+		&result.EstateId,
+		&result.Version,
+		&result.Plan,
+	)
+	if err != nil {
+		return
+	}
+	return
+}
+
+func (r *Repository) CreateDronePlan(ctx context.Context, input DronePlan) (err error) {
+	_, err = r.Db.ExecContext(ctx, `
+		INSERT INTO drone_plans (estate_id, version, plan)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (estate_id, version) DO NOTHING;
+	`,
+		input.EstateId,
+		input.Version,
+		string(input.Plan),
+	)
+	return
+}
+
+func (r *Repository) CreateFlight(ctx context.Context, input Flight) (result Flight, err error) {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO flights (id, estate_id)
+		VALUES ($1, $2)
+		returning created_at;
+	`,
+		input.Id,
+		input.EstateId,
+	).Scan(&input.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("flight_samples", "flight_id", "seq", "recorded_at", "x", "y", "altitude"))
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	for seq, sample := range input.Samples {
+		_, err = stmt.ExecContext(ctx, input.Id, seq, sample.Time, sample.X, sample.Y, sample.Altitude)
+		if err != nil {
+			return
+		}
+	}
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	result = input
+
+	return
+}
+
+func (r *Repository) GetFlightById(ctx context.Context, estateId, id string) (result Flight, err error) {
+	err = r.Db.QueryRowContext(ctx, `
+		SELECT id, estate_id, created_at FROM flights WHERE id = $1 AND estate_id = $2;
+	`, id, estateId).Scan(
+		&result.Id,
+		&result.EstateId,
+		&result.CreatedAt,
+	)
+	if err != nil {
+		return
+	}
+
+	rows, err := r.Db.QueryContext(ctx, `
+        SELECT recorded_at, x, y, altitude FROM flight_samples WHERE flight_id = $1 ORDER BY seq;
+    `, id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sample FlightSample
+		err = rows.Scan(
+			&sample.Time,
+			&sample.X,
+			&sample.Y,
+			&sample.Altitude,
+		)
+		if err != nil {
+			return
+		}
+		result.Samples = append(result.Samples, sample)
+	}
+
+	return
+}
+
+func (r *Repository) CreateMission(ctx context.Context, input Mission) (result Mission, err error) {
+	err = r.Db.QueryRowContext(ctx, `
+		INSERT INTO missions (id, estate_id, drone_id, status, parameters, trees_digest, version, plan)
+		VALUES ($1, $2, $3, $4, $5, $6::BIT(128), $7, $8)
+		returning created_at;
+	`,
+		input.Id,
+		input.EstateId,
+		input.DroneId,
+		input.Status,
+		string(input.Parameters),
+		input.TreesDigest,
+		input.Version,
+		string(input.Plan),
+	).Scan(&input.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	result = input
+
+	return
+}
+
+// missionColumns are the columns scanned by scanMission
+const missionColumns = `id, estate_id, drone_id, status, parameters, trees_digest::text, version, plan, created_at, started_at, ended_at`
+
+func (r *Repository) GetMissionsByEstateId(ctx context.Context, id string, status MissionStatus) (result []Mission, err error) {
+	rows, err := r.Db.QueryContext(ctx, `
+        SELECT `+missionColumns+` FROM missions
+        WHERE estate_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY created_at, id;
+    `, id, status)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mission Mission
+		mission, err = scanMission(rows)
+		if err != nil {
+			return
+		}
+		result = append(result, mission)
+	}
+
+	return
+}
+
+func (r *Repository) GetMissionById(ctx context.Context, estateId, id string) (result Mission, err error) {
+	return scanMission(r.Db.QueryRowContext(ctx, `
+		SELECT `+missionColumns+` FROM missions WHERE id = $1 AND estate_id = $2;
+	`, id, estateId))
+}
+
+// UpdateMissionStatus moves the mission from a status to another and stamps
+// the start or the end of the flight, sql.ErrNoRows is returned when the
+// mission is no longer in the from status.
+func (r *Repository) UpdateMissionStatus(ctx context.Context, estateId, id string, from, to MissionStatus) (result Mission, err error) {
+	return scanMission(r.Db.QueryRowContext(ctx, `
+		UPDATE missions SET
+			status = $4,
+			started_at = CASE WHEN $4 = 'in_flight' THEN NOW() ELSE started_at END,
+			ended_at = CASE WHEN $4 IN ('completed', 'aborted') THEN NOW() ELSE ended_at END
+		WHERE id = $1 AND estate_id = $2 AND status = $3
+		returning `+missionColumns+`;
+	`, id, estateId, from, to))
+}
+
+// scanMission scans a row of the mission columns
+func scanMission(row interface{ Scan(...any) error }) (result Mission, err error) {
+	err = row.Scan(
+		&result.Id,
+		&result.EstateId,
+		&result.DroneId,
+		&result.Status,
+		&result.Parameters,
+		&result.TreesDigest,
+		&result.Version,
+		&result.Plan,
+		&result.CreatedAt,
+		&result.StartedAt,
+		&result.EndedAt,
+	)
+	return
+}
+
+// formatPolygon formats the vertices as a PostgreSQL polygon, ((x1,y1),...,(xn,yn))
+func formatPolygon(vertices []Point) string {
+	points := make([]string, 0, len(vertices))
+	for _, vertex := range vertices {
+		points = append(points, fmt.Sprintf("(%g,%g)", vertex.X, vertex.Y))
+	}
+
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+// parsePolygon parses the vertices of a PostgreSQL polygon
+func parsePolygon(polygon string) (vertices []Point, err error) {
+	values := strings.Split(strings.NewReplacer("(", "", ")", "").Replace(polygon), ",")
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("invalid polygon %q", polygon)
+	}
+
+	for i := 0; i < len(values); i += 2 {
+		var vertex Point
+		vertex.X, err = strconv.ParseFloat(strings.TrimSpace(values[i]), 64)
+		if err != nil {
+			return
+		}
+		vertex.Y, err = strconv.ParseFloat(strings.TrimSpace(values[i+1]), 64)
+		if err != nil {
+			return
+		}
+		vertices = append(vertices, vertex)
+	}
+
+	return
+}
+
+func (r *Repository) CreateDrone(ctx context.Context, input Drone) (result Drone, err error) {
+	err = r.Db.QueryRowContext(ctx, `
+		INSERT INTO drones (id, model, max_range, cruise_speed, climb_rate, payload_capacity)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		returning created_at;
+	`,
+		input.Id,
+		input.Model,
+		input.MaxRange,
+		input.CruiseSpeed,
+		input.ClimbRate,
+		input.PayloadCapacity,
+	).Scan(&input.CreatedAt)
+	if err != nil {
+		return
+	}
+
+	result = input
+
+	return
+}
+
+// droneColumns are the columns scanned by scanDrone
+const droneColumns = `id, model, max_range, cruise_speed, climb_rate, payload_capacity, created_at`
+
+func (r *Repository) GetDrones(ctx context.Context) (result []Drone, err error) {
+	rows, err := r.Db.QueryContext(ctx, `
+        SELECT `+droneColumns+` FROM drones ORDER BY created_at, id;
+    `)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var drone Drone
+		drone, err = scanDrone(rows)
+		if err != nil {
+			return
+		}
+		result = append(result, drone)
+	}
+
+	return
+}
+
+func (r *Repository) GetDroneById(ctx context.Context, id string) (result Drone, err error) {
+	return scanDrone(r.Db.QueryRowContext(ctx, `
+		SELECT `+droneColumns+` FROM drones WHERE id = $1;
+	`, id))
+}
+
+// scanDrone scans a row of the drone columns
+func scanDrone(row interface{ Scan(...any) error }) (result Drone, err error) {
+	err = row.Scan(
+		&result.Id,
+		&result.Model,
+		&result.MaxRange,
+		&result.CruiseSpeed,
+		&result.ClimbRate,
+		&result.PayloadCapacity,
+		&result.CreatedAt,
+	)
+	return
+}
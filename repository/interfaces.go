@@ -11,5 +11,21 @@ type RepositoryInterface interface {
 	CreateEstateTree(ctx context.Context, input EstateTree) (result EstateTree, err error)
 	GetStatsByEstateId(ctx context.Context, id string) (result StatsEstate, err error)
 	GetEstateById(ctx context.Context, id string) (result Estate, err error)
+	UpdateEstateDosing(ctx context.Context, input Estate) (err error)
 	GetTreesByEstateId(ctx context.Context, id string) (result []EstateTree, err error)
+	CreateNoFlyZone(ctx context.Context, input NoFlyZone) (result NoFlyZone, err error)
+	GetNoFlyZonesByEstateId(ctx context.Context, id string) (result []NoFlyZone, err error)
+	ReplacePlotElevations(ctx context.Context, estateId string, input []PlotElevation) (err error)
+	GetPlotElevationsByEstateId(ctx context.Context, id string) (result []PlotElevation, err error)
+	GetDronePlan(ctx context.Context, estateId, version string) (result DronePlan, err error)
+	CreateDronePlan(ctx context.Context, input DronePlan) (err error)
+	CreateFlight(ctx context.Context, input Flight) (result Flight, err error)
+	GetFlightById(ctx context.Context, estateId, id string) (result Flight, err error)
+	CreateMission(ctx context.Context, input Mission) (result Mission, err error)
+	GetMissionsByEstateId(ctx context.Context, id string, status MissionStatus) (result []Mission, err error)
+	GetMissionById(ctx context.Context, estateId, id string) (result Mission, err error)
+	UpdateMissionStatus(ctx context.Context, estateId, id string, from, to MissionStatus) (result Mission, err error)
+	CreateDrone(ctx context.Context, input Drone) (result Drone, err error)
+	GetDrones(ctx context.Context) (result []Drone, err error)
+	GetDroneById(ctx context.Context, id string) (result Drone, err error)
 }
@@ -40,6 +40,35 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// CreateDrone mocks base method.
+func (m *MockRepositoryInterface) CreateDrone(ctx context.Context, input Drone) (Drone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDrone", ctx, input)
+	ret0, _ := ret[0].(Drone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDrone indicates an expected call of CreateDrone.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateDrone(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDrone", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateDrone), ctx, input)
+}
+
+// CreateDronePlan mocks base method.
+func (m *MockRepositoryInterface) CreateDronePlan(ctx context.Context, input DronePlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDronePlan", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDronePlan indicates an expected call of CreateDronePlan.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateDronePlan(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDronePlan", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateDronePlan), ctx, input)
+}
+
 // CreateEstate mocks base method.
 func (m *MockRepositoryInterface) CreateEstate(ctx context.Context, input Estate) (Estate, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +99,96 @@ func (mr *MockRepositoryInterfaceMockRecorder) CreateEstateTree(ctx, input any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEstateTree", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateEstateTree), ctx, input)
 }
 
+// CreateFlight mocks base method.
+func (m *MockRepositoryInterface) CreateFlight(ctx context.Context, input Flight) (Flight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFlight", ctx, input)
+	ret0, _ := ret[0].(Flight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFlight indicates an expected call of CreateFlight.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateFlight(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFlight", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateFlight), ctx, input)
+}
+
+// CreateMission mocks base method.
+func (m *MockRepositoryInterface) CreateMission(ctx context.Context, input Mission) (Mission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMission", ctx, input)
+	ret0, _ := ret[0].(Mission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMission indicates an expected call of CreateMission.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateMission(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMission", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateMission), ctx, input)
+}
+
+// CreateNoFlyZone mocks base method.
+func (m *MockRepositoryInterface) CreateNoFlyZone(ctx context.Context, input NoFlyZone) (NoFlyZone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNoFlyZone", ctx, input)
+	ret0, _ := ret[0].(NoFlyZone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNoFlyZone indicates an expected call of CreateNoFlyZone.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateNoFlyZone(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNoFlyZone", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateNoFlyZone), ctx, input)
+}
+
+// GetDroneById mocks base method.
+func (m *MockRepositoryInterface) GetDroneById(ctx context.Context, id string) (Drone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDroneById", ctx, id)
+	ret0, _ := ret[0].(Drone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDroneById indicates an expected call of GetDroneById.
+func (mr *MockRepositoryInterfaceMockRecorder) GetDroneById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDroneById", reflect.TypeOf((*MockRepositoryInterface)(nil).GetDroneById), ctx, id)
+}
+
+// GetDronePlan mocks base method.
+func (m *MockRepositoryInterface) GetDronePlan(ctx context.Context, estateId, version string) (DronePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDronePlan", ctx, estateId, version)
+	ret0, _ := ret[0].(DronePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDronePlan indicates an expected call of GetDronePlan.
+func (mr *MockRepositoryInterfaceMockRecorder) GetDronePlan(ctx, estateId, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDronePlan", reflect.TypeOf((*MockRepositoryInterface)(nil).GetDronePlan), ctx, estateId, version)
+}
+
+// GetDrones mocks base method.
+func (m *MockRepositoryInterface) GetDrones(ctx context.Context) ([]Drone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrones", ctx)
+	ret0, _ := ret[0].([]Drone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrones indicates an expected call of GetDrones.
+func (mr *MockRepositoryInterfaceMockRecorder) GetDrones(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrones", reflect.TypeOf((*MockRepositoryInterface)(nil).GetDrones), ctx)
+}
+
 // GetEstateById mocks base method.
 func (m *MockRepositoryInterface) GetEstateById(ctx context.Context, id string) (Estate, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +204,81 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetEstateById(ctx, id any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEstateById", reflect.TypeOf((*MockRepositoryInterface)(nil).GetEstateById), ctx, id)
 }
 
+// GetFlightById mocks base method.
+func (m *MockRepositoryInterface) GetFlightById(ctx context.Context, estateId, id string) (Flight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFlightById", ctx, estateId, id)
+	ret0, _ := ret[0].(Flight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFlightById indicates an expected call of GetFlightById.
+func (mr *MockRepositoryInterfaceMockRecorder) GetFlightById(ctx, estateId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFlightById", reflect.TypeOf((*MockRepositoryInterface)(nil).GetFlightById), ctx, estateId, id)
+}
+
+// GetMissionById mocks base method.
+func (m *MockRepositoryInterface) GetMissionById(ctx context.Context, estateId, id string) (Mission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMissionById", ctx, estateId, id)
+	ret0, _ := ret[0].(Mission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMissionById indicates an expected call of GetMissionById.
+func (mr *MockRepositoryInterfaceMockRecorder) GetMissionById(ctx, estateId, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMissionById", reflect.TypeOf((*MockRepositoryInterface)(nil).GetMissionById), ctx, estateId, id)
+}
+
+// GetMissionsByEstateId mocks base method.
+func (m *MockRepositoryInterface) GetMissionsByEstateId(ctx context.Context, id string, status MissionStatus) ([]Mission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMissionsByEstateId", ctx, id, status)
+	ret0, _ := ret[0].([]Mission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMissionsByEstateId indicates an expected call of GetMissionsByEstateId.
+func (mr *MockRepositoryInterfaceMockRecorder) GetMissionsByEstateId(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMissionsByEstateId", reflect.TypeOf((*MockRepositoryInterface)(nil).GetMissionsByEstateId), ctx, id, status)
+}
+
+// GetNoFlyZonesByEstateId mocks base method.
+func (m *MockRepositoryInterface) GetNoFlyZonesByEstateId(ctx context.Context, id string) ([]NoFlyZone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoFlyZonesByEstateId", ctx, id)
+	ret0, _ := ret[0].([]NoFlyZone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoFlyZonesByEstateId indicates an expected call of GetNoFlyZonesByEstateId.
+func (mr *MockRepositoryInterfaceMockRecorder) GetNoFlyZonesByEstateId(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoFlyZonesByEstateId", reflect.TypeOf((*MockRepositoryInterface)(nil).GetNoFlyZonesByEstateId), ctx, id)
+}
+
+// GetPlotElevationsByEstateId mocks base method.
+func (m *MockRepositoryInterface) GetPlotElevationsByEstateId(ctx context.Context, id string) ([]PlotElevation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlotElevationsByEstateId", ctx, id)
+	ret0, _ := ret[0].([]PlotElevation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlotElevationsByEstateId indicates an expected call of GetPlotElevationsByEstateId.
+func (mr *MockRepositoryInterfaceMockRecorder) GetPlotElevationsByEstateId(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlotElevationsByEstateId", reflect.TypeOf((*MockRepositoryInterface)(nil).GetPlotElevationsByEstateId), ctx, id)
+}
+
 // GetStatsByEstateId mocks base method.
 func (m *MockRepositoryInterface) GetStatsByEstateId(ctx context.Context, id string) (StatsEstate, error) {
 	m.ctrl.T.Helper()
@@ -114,3 +308,46 @@ func (mr *MockRepositoryInterfaceMockRecorder) GetTreesByEstateId(ctx, id any) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTreesByEstateId", reflect.TypeOf((*MockRepositoryInterface)(nil).GetTreesByEstateId), ctx, id)
 }
+
+// ReplacePlotElevations mocks base method.
+func (m *MockRepositoryInterface) ReplacePlotElevations(ctx context.Context, estateId string, input []PlotElevation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePlotElevations", ctx, estateId, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePlotElevations indicates an expected call of ReplacePlotElevations.
+func (mr *MockRepositoryInterfaceMockRecorder) ReplacePlotElevations(ctx, estateId, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePlotElevations", reflect.TypeOf((*MockRepositoryInterface)(nil).ReplacePlotElevations), ctx, estateId, input)
+}
+
+// UpdateEstateDosing mocks base method.
+func (m *MockRepositoryInterface) UpdateEstateDosing(ctx context.Context, input Estate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEstateDosing", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEstateDosing indicates an expected call of UpdateEstateDosing.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateEstateDosing(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEstateDosing", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateEstateDosing), ctx, input)
+}
+
+// UpdateMissionStatus mocks base method.
+func (m *MockRepositoryInterface) UpdateMissionStatus(ctx context.Context, estateId, id string, from, to MissionStatus) (Mission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMissionStatus", ctx, estateId, id, from, to)
+	ret0, _ := ret[0].(Mission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMissionStatus indicates an expected call of UpdateMissionStatus.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateMissionStatus(ctx, estateId, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMissionStatus", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateMissionStatus), ctx, estateId, id, from, to)
+}
@@ -1,9 +1,25 @@
 package repository
 
+import "time"
+
 type Estate struct {
-	Id     string
-	Width  int
-	Length int
+	Id        string
+	Width     int
+	Length    int
+	Clearance int
+	LookAhead int
+	// DoseBase and DosePerMeter are the litres sprayed on a tree and the
+	// litres added for every meter of its height.
+	DoseBase     float64
+	DosePerMeter float64
+	// MaxAltitude is the altitude in meters drones may not fly above.
+	MaxAltitude int
+	// TreesDigest changes whenever a tree of the estate is created, updated
+	// or deleted.
+	TreesDigest string
+	// ElevationsDigest changes whenever the elevation of a plot of the estate
+	// is set, changed or removed.
+	ElevationsDigest string
 }
 
 type EstateTree struct {
@@ -12,6 +28,8 @@ type EstateTree struct {
 	X        int
 	Y        int
 	Height   int
+	// CanopyDiameter is the width in meters of the canopy, 0 when unknown.
+	CanopyDiameter float64
 }
 
 type StatsEstate struct {
@@ -20,3 +38,91 @@ type StatsEstate struct {
 	Min    int
 	Median float64
 }
+
+type NoFlyZone struct {
+	Id       string
+	EstateId string
+	Name     string
+	Vertices []Point
+}
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+type DronePlan struct {
+	EstateId string
+	Version  string
+	// Plan is the drone plan response as JSON.
+	Plan []byte
+}
+
+type Flight struct {
+	Id        string
+	EstateId  string
+	CreatedAt time.Time
+	// Samples is the telemetry log of the flight in time order.
+	Samples []FlightSample
+}
+
+// MissionStatus is the lifecycle status of a mission.
+type MissionStatus string
+
+const (
+	MissionScheduled MissionStatus = "scheduled"
+	MissionInFlight  MissionStatus = "in_flight"
+	MissionCompleted MissionStatus = "completed"
+	MissionAborted   MissionStatus = "aborted"
+)
+
+type Mission struct {
+	Id       string
+	EstateId string
+	// DroneId is the registered drone flying the mission.
+	DroneId string
+	Status  MissionStatus
+	// Parameters are the drone plan parameters as JSON.
+	Parameters []byte
+	// TreesDigest and Version are the trees digest of the estate and the
+	// version of the drone plan when the mission was planned.
+	TreesDigest string
+	Version     string
+	// Plan is the drone plan response as JSON.
+	Plan      []byte
+	CreatedAt time.Time
+	StartedAt *time.Time
+	EndedAt   *time.Time
+}
+
+// FlightSample is a telemetry record at plot-relative coordinates, the center
+// of plot (x, y) being at (x, y), and at an altitude in meters.
+type FlightSample struct {
+	Time     time.Time
+	X        float64
+	Y        float64
+	Altitude float64
+}
+
+// PlotElevation is the altitude in meters of the ground of a plot above the
+// datum of the terrain model.
+type PlotElevation struct {
+	X         int
+	Y         int
+	Elevation float64
+}
+
+// Drone is a registered drone and its specs.
+type Drone struct {
+	Id    string
+	Model string
+	// MaxRange is the distance in meters the drone flies on one battery.
+	MaxRange int
+	// CruiseSpeed and ClimbRate are the horizontal and vertical speeds in
+	// meters per second.
+	CruiseSpeed float64
+	ClimbRate   float64
+	// PayloadCapacity is the litres of chemical the tank of the drone holds.
+	PayloadCapacity float64
+	CreatedAt       time.Time
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -83,6 +85,292 @@ func getTestCases() []TestCase {
 				},
 			},
 		},
+		{
+			Name: "Drone Plan Version Changes With Trees",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestGetDronePlan(0),
+					Expect:  ExpectGetDronePlanOk(62),
+				},
+				{
+					Request: SendRequestGetDronePlan(0),
+					Expect:  ExpectGetDronePlanVersion(2, true),
+				},
+				{
+					Request: SendRequestNewTree(20, 3, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestGetDronePlan(0),
+					Expect:  ExpectGetDronePlanVersion(2, false),
+				},
+			},
+		},
+		{
+			Name: "Upload Flight Log",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewFlight([][3]float64{{1, 1, 0}, {1, 1, 1}, {3, 1, 1}, {3, 1, 0}}),
+					Expect:  ExpectNewFlightOk(60, 22),
+				},
+			},
+		},
+		{
+			Name: "Test Error: Simulation Compression Out of Range",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestGetDronePlanSimulation(0.5),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
+		{
+			Name: "Mission Lifecycle",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewDrone("Sprayer", 200, 5),
+					Expect:  ExpectNewDroneOk(),
+				},
+				{
+					Request: SendRequestNewMission(0, 50),
+					Expect:  ExpectBadRequest(),
+				},
+				{
+					Request: SendRequestNewMission(1, 50),
+					Expect:  ExpectNewMissionOk(1, 42),
+				},
+				{
+					Request: SendRequestMissionTransition(3, "completed"),
+					Expect:  ExpectConflict(),
+				},
+				{
+					Request: SendRequestMissionTransition(3, "in_flight"),
+					Expect:  ExpectMissionStatus("in_flight"),
+				},
+				{
+					Request: SendRequestMissionTransition(3, "completed"),
+					Expect:  ExpectMissionStatus("completed"),
+				},
+				{
+					Request: SendRequestMissionTransition(3, "aborted"),
+					Expect:  ExpectConflict(),
+				},
+			},
+		},
+		{
+			Name: "Spraying Sorties",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(20, 3, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 4, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestUpdateDosing(1, 0.1),
+					Expect:  ExpectUpdateDosingOk(),
+				},
+				{
+					Request: SendRequestGetSprayPlan(5),
+					Expect:  ExpectGetSprayPlanOk(2, 7, 204),
+				},
+				{
+					Request: SendRequestGetSprayPlan(2.5),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
+		{
+			Name: "Terrain Elevation",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(3, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestUpdateElevation("ncols 3\nnrows 1\nxllcorner 0\nyllcorner 0\ncellsize 10\nNODATA_value -9999\n-9999 5 9.6\n"),
+					Expect:  ExpectUpdateElevationOk(2, 5, 9.6),
+				},
+				{
+					Request: SendRequestGetDronePlan(0),
+					Expect:  ExpectGetDronePlanOk(42),
+				},
+				{
+					Request: SendRequestUpdateElevation("ncols 4\nnrows 1\nxllcorner 0\nyllcorner 0\ncellsize 10\n0 5 10 15\n"),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
+		{
+			Name: "Altitude Ceiling",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstateMaxAltitude(5, 1, 20),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(20, 3, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestGetDronePlanCeiling("flag"),
+					Expect:  ExpectGetDronePlanCeilingViolations(http.StatusOK, 3, 1, 21),
+				},
+				{
+					Request: SendRequestGetDronePlanCeiling("refuse"),
+					Expect:  ExpectGetDronePlanCeilingViolations(http.StatusUnprocessableEntity, 3, 1, 21),
+				},
+			},
+		},
+		{
+			Name: "Route Validation",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(20, 3, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 4, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestValidateRoute([][3]float64{{1, 1, 0}, {1, 1, 21}, {5, 1, 21}, {5, 1, 0}}),
+					Expect:  ExpectValidateRouteOk(true, 0, 0, 0, 82),
+				},
+				{
+					Request: SendRequestValidateRoute([][3]float64{{1, 1, 0}, {1, 1, 11}, {3, 1, 11}, {6, 1, 11}, {6, 1, 0}}),
+					Expect:  ExpectValidateRouteOk(false, 1, 0, 2, 72),
+				},
+				{
+					// Every waypoint clears its plot, the leg between them
+					// flies through the trees.
+					Request: SendRequestValidateRoute([][3]float64{{1, 1, 0}, {1, 1, 2}, {5, 1, 2}, {5, 1, 0}}),
+					Expect:  ExpectValidateRouteOk(false, 0, 1, 0, 44),
+				},
+				{
+					Request: SendRequestValidateRoute([][3]float64{{1, 1, 0}}),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
+		{
+			Name: "Drone Registry",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 2, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(20, 3, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(10, 4, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestUpdateDosing(1, 0.1),
+					Expect:  ExpectUpdateDosingOk(),
+				},
+				{
+					Request: SendRequestNewDrone("Sprayer", 200, 5),
+					Expect:  ExpectNewDroneOk(),
+				},
+				{
+					Request: SendRequestGetDrone(5),
+					Expect:  ExpectGetDroneOk("Sprayer"),
+				},
+				{
+					Request: SendRequestGetSprayPlanWithDrone(5),
+					Expect:  ExpectGetSprayPlanOk(2, 7, 204),
+				},
+				{
+					Request: SendRequestGetDronePlanWithDrone(5, ""),
+					Expect:  ExpectGetDronePlanSortiesOk(82, 1),
+				},
+				{
+					Request: SendRequestGetDronePlanWithDrone(5, "&speed=5"),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
+		{
+			Name: "Tree Canopy",
+			Steps: []TestCaseStep{
+				{
+					Request: SendRequestNewEstate(5, 1),
+					Expect:  ExpectNewEstateOk(),
+				},
+				{
+					Request: SendRequestNewTreeCanopy(20, 2, 1, 15),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					Request: SendRequestNewTree(20, 4, 1),
+					Expect:  ExpectNewTreeOk(),
+				},
+				{
+					// The canopy reaches the plot between the trees, the drone
+					// stays above them instead of diving down to it.
+					Request: SendRequestGetDronePlan(0),
+					Expect:  ExpectGetDronePlanOk(82),
+				},
+				{
+					Request: SendRequestNewTreeCanopy(10, 5, 1, 150),
+					Expect:  ExpectBadRequest(),
+				},
+			},
+		},
 		CreateNormalTestCase("Normal 1", []any{
 			[]any{CreateEstate, 10, 20},
 			[]any{CreateTree, 10, 5, 5},
@@ -95,6 +383,15 @@ func getTestCases() []TestCase {
 			[]any{CreateTree, 10, 4, 1},
 			[]any{GetStats, 3, 10, 20, 10},
 			[]any{GetDronePlan, 0, 82},
+			[]any{GetDronePlanRest, 50, 41, 3, 1},
+			[]any{GetDronePlanRoute, 82, 8},
+			[]any{GetInspectionPlan, 1, 82, 5},
+			[]any{GetDronePlanEstimate, 4.0, 17.5},
+			[]any{GetCapturePlan, 10, 5},
+			[]any{GetDronePlanResume, 3, 1, 41, 82},
+			[]any{GetDronePlanStrategy, "tree-tour", 72},
+			[]any{GetDronePlanRouteStrategy, "tree-tour", 72},
+			[]any{GetStrategies, 82, 82, 72},
 		}),
 	}
 }
@@ -137,6 +434,15 @@ const (
 	CreateTree
 	GetStats
 	GetDronePlan
+	GetDronePlanRest
+	GetDronePlanRoute
+	GetInspectionPlan
+	GetDronePlanEstimate
+	GetCapturePlan
+	GetDronePlanResume
+	GetDronePlanStrategy
+	GetDronePlanRouteStrategy
+	GetStrategies
 )
 
 func CreateNormalTestCase(name string, a []any) TestCase {
@@ -165,6 +471,51 @@ func CreateNormalTestCase(name string, a []any) TestCase {
 				Request: SendRequestGetDronePlan(step.([]any)[1].(int)),
 				Expect:  ExpectGetDronePlanOk(step.([]any)[2].(int)),
 			})
+		case GetDronePlanRest:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlanMaxDistance(step.([]any)[1].(int)),
+				Expect:  ExpectGetDronePlanRestOk(step.([]any)[2].(int), step.([]any)[3].(int), step.([]any)[4].(int)),
+			})
+		case GetDronePlanRoute:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlanRoute(),
+				Expect:  ExpectGetDronePlanRouteOk(step.([]any)[1].(int), step.([]any)[2].(int)),
+			})
+		case GetDronePlanEstimate:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlan(0),
+				Expect:  ExpectGetDronePlanEstimateOk(step.([]any)[1].(float64), step.([]any)[2].(float64)),
+			})
+		case GetDronePlanResume:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlanResume(step.([]any)[1].(int), step.([]any)[2].(int)),
+				Expect:  ExpectGetDronePlanResumeOk(step.([]any)[3].(int), step.([]any)[4].(int)),
+			})
+		case GetDronePlanStrategy:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlanStrategy(step.([]any)[1].(string)),
+				Expect:  ExpectGetDronePlanOk(step.([]any)[2].(int)),
+			})
+		case GetDronePlanRouteStrategy:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetDronePlanRouteStrategy(step.([]any)[1].(string)),
+				Expect:  ExpectGetDronePlanOk(step.([]any)[2].(int)),
+			})
+		case GetStrategies:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetStrategies(),
+				Expect:  ExpectGetStrategiesOk(step.([]any)[1].(int), step.([]any)[2].(int), step.([]any)[3].(int)),
+			})
+		case GetCapturePlan:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetCapturePlan(step.([]any)[1].(int)),
+				Expect:  ExpectGetCapturePlanOk(step.([]any)[2].(int)),
+			})
+		case GetInspectionPlan:
+			tc.Steps = append(tc.Steps, TestCaseStep{
+				Request: SendRequestGetInspectionPlan(step.([]any)[1].(int)),
+				Expect:  ExpectGetInspectionPlanOk(step.([]any)[1].(int), step.([]any)[2].(int), step.([]any)[3].(int)),
+			})
 		}
 
 	}
@@ -183,6 +534,19 @@ func SendRequestNewEstate(length, width int) RequestFunc {
 	}
 }
 
+func SendRequestNewEstateMaxAltitude(length, width, maxAltitude int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		req := map[string]int{
+			"length":       length,
+			"width":        width,
+			"max_altitude": maxAltitude,
+		}
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate", bytes.NewReader(body))
+	}
+}
+
 func ExpectNewEstateOk() ExpectFunc {
 	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
 		RequireReturnIsUUID(t, resp, data)
@@ -203,6 +567,21 @@ func SendRequestNewTree(height, x, y int) RequestFunc {
 	}
 }
 
+func SendRequestNewTreeCanopy(height, x, y int, canopyDiameter float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		req := map[string]any{
+			"height":          height,
+			"x":               x,
+			"y":               y,
+			"canopy_diameter": canopyDiameter,
+		}
+		id := tc.Steps[0].Result["id"].(string)
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate/"+id+"/tree", bytes.NewReader(body))
+	}
+}
+
 func ExpectNewTreeOk() ExpectFunc {
 	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
 		RequireReturnIsUUID(t, resp, data)
@@ -236,12 +615,377 @@ func SendRequestGetDronePlan(distance int) RequestFunc {
 	}
 }
 
+func SendRequestGetDronePlanMaxDistance(maxDistance int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan?max_distance=%d", ApiUrl, id, maxDistance), nil)
+	}
+}
+
 func ExpectGetDronePlanOk(distance int) ExpectFunc {
 	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
 		RequireDistance(t, resp, data, distance)
 	}
 }
 
+func ExpectGetDronePlanRestOk(distance, x, y int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireDistance(t, resp, data, distance)
+		RequireRest(t, data, x, y)
+	}
+}
+
+func ExpectGetDronePlanEstimateOk(horizontal, vertical float64) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		estimate := data["estimate"].(map[string]any)
+		require.InDelta(t, horizontal, estimate["horizontal_duration"].(float64), 1e-9)
+		require.InDelta(t, vertical, estimate["vertical_duration"].(float64), 1e-9)
+		require.InDelta(t, horizontal+vertical, estimate["duration"].(float64), 1e-9)
+	}
+}
+
+// ExpectGetDronePlanVersion compares the plan version with the one of a
+// previous step
+func ExpectGetDronePlanVersion(step int, same bool) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		previous := tc.Steps[step].Result["version"].(string)
+		if same {
+			require.Equal(t, previous, data["version"])
+		} else {
+			require.NotEqual(t, previous, data["version"])
+		}
+	}
+}
+
+func SendRequestGetDronePlanCeiling(policy string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan?altitude_ceiling=%s", ApiUrl, id, policy), nil)
+	}
+}
+
+func ExpectGetDronePlanCeilingViolations(status, x, y, altitude int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, status, resp.StatusCode)
+		key := "ceiling_violations"
+		if status != http.StatusOK {
+			key = "violations"
+		}
+		require.Len(t, data[key], 1)
+		violation := data[key].([]any)[0].(map[string]any)
+		require.Equal(t, float64(x), violation["x"])
+		require.Equal(t, float64(y), violation["y"])
+		require.Equal(t, float64(altitude), violation["altitude"])
+	}
+}
+
+func SendRequestGetDronePlanRoute() RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", ApiUrl+"/estate/"+id+"/drone-plan/route", nil)
+	}
+}
+
+func SendRequestGetDronePlanSimulation(compression float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/simulate?compression=%g", ApiUrl, id, compression), nil)
+	}
+}
+
+func ExpectGetDronePlanRouteOk(distance, waypoints int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireDistance(t, resp, data, distance)
+		require.Len(t, data["waypoints"], waypoints)
+	}
+}
+
+func SendRequestGetDronePlanResume(x, y int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan?resume_x=%d&resume_y=%d", ApiUrl, id, x, y), nil)
+	}
+}
+
+func ExpectGetDronePlanResumeOk(distance, total int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resume := data["resume"].(map[string]any)
+		require.Equal(t, distance, int(resume["distance"].(float64)))
+		require.Equal(t, total, int(resume["total_distance"].(float64)))
+	}
+}
+
+func SendRequestGetDronePlanStrategy(strategy string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan?strategy=%s", ApiUrl, id, strategy), nil)
+	}
+}
+
+func SendRequestGetDronePlanRouteStrategy(strategy string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/route?strategy=%s", ApiUrl, id, strategy), nil)
+	}
+}
+
+func SendRequestGetStrategies() RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/strategies", ApiUrl, id), nil)
+	}
+}
+
+func ExpectGetStrategiesOk(zigzag, spiral, treeTour int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		distances := map[string]int{}
+		for _, strategy := range data["strategies"].([]any) {
+			strategy := strategy.(map[string]any)
+			distances[strategy["strategy"].(string)] = int(strategy["distance"].(float64))
+		}
+		require.Equal(t, map[string]int{"zigzag": zigzag, "spiral": spiral, "tree-tour": treeTour}, distances)
+		require.Equal(t, "tree-tour", data["shortest"])
+	}
+}
+
+func SendRequestGetCapturePlan(clearance int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/captures?field_of_view=90&overlap=50&clearance=%d", ApiUrl, id, clearance), nil)
+	}
+}
+
+func ExpectGetCapturePlanOk(images int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, images, int(data["image_count"].(float64)))
+		require.Len(t, data["captures"].([]any), images)
+	}
+}
+
+func SendRequestGetInspectionPlan(tallest int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		url := fmt.Sprintf("%s/estate/%s/drone-plan/inspection?tallest=%d", ApiUrl, id, tallest)
+		return http.NewRequest("GET", url, nil)
+	}
+}
+
+func ExpectGetInspectionPlanOk(trees, distance, waypoints int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireDistance(t, resp, data, distance)
+		require.Len(t, data["trees"], trees)
+		require.Len(t, data["waypoints"], waypoints)
+	}
+}
+
+func SendRequestNewFlight(points [][3]float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+		samples := make([]map[string]any, 0, len(points))
+		for i, point := range points {
+			samples = append(samples, map[string]any{
+				"timestamp": start.Add(time.Duration(i) * time.Second),
+				"x":         point[0],
+				"y":         point[1],
+				"altitude":  point[2],
+			})
+		}
+
+		id := tc.Steps[0].Result["id"].(string)
+		body, err := json.Marshal(map[string]any{"samples": samples})
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate/"+id+"/flights", bytes.NewReader(body))
+	}
+}
+
+func ExpectNewFlightOk(coverage, distance float64) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireReturnIsUUID(t, resp, data)
+		report := data["report"].(map[string]any)
+		require.InDelta(t, coverage, report["coverage"].(float64), 1e-9)
+		require.InDelta(t, distance, report["distance"].(float64), 1e-9)
+	}
+}
+
+func SendRequestValidateRoute(points [][3]float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		waypoints := make([]map[string]float64, 0, len(points))
+		for _, point := range points {
+			waypoints = append(waypoints, map[string]float64{"x": point[0], "y": point[1], "altitude": point[2]})
+		}
+
+		id := tc.Steps[0].Result["id"].(string)
+		body, err := json.Marshal(map[string]any{"waypoints": waypoints})
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate/"+id+"/drone-plan/validate", bytes.NewReader(body))
+	}
+}
+
+func ExpectValidateRouteOk(valid bool, violations, segments, outOfBounds int, distance float64) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, valid, data["valid"])
+		require.Len(t, data["clearance_violations"], violations)
+		require.Len(t, data["segment_violations"], segments)
+		require.Len(t, data["out_of_bounds"], outOfBounds)
+		require.InDelta(t, distance, data["distance"].(float64), 1e-9)
+	}
+}
+
+func SendRequestNewDrone(model string, maxRange int, payloadCapacity float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		body, err := json.Marshal(map[string]any{
+			"model":            model,
+			"max_range":        maxRange,
+			"cruise_speed":     10,
+			"climb_rate":       3,
+			"payload_capacity": payloadCapacity,
+		})
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/drones", bytes.NewReader(body))
+	}
+}
+
+func ExpectNewDroneOk() ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireReturnIsUUID(t, resp, data)
+	}
+}
+
+func SendRequestGetDrone(step int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[step].Result["id"].(string)
+		return http.NewRequest("GET", ApiUrl+"/drones/"+id, nil)
+	}
+}
+
+func ExpectGetDroneOk(model string) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, model, data["model"])
+	}
+}
+
+func SendRequestGetSprayPlanWithDrone(step int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		droneId := tc.Steps[step].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/spray?drone_id=%s", ApiUrl, id, droneId), nil)
+	}
+}
+
+func SendRequestGetDronePlanWithDrone(step int, query string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		droneId := tc.Steps[step].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan?drone_id=%s%s", ApiUrl, id, droneId, query), nil)
+	}
+}
+
+func ExpectGetDronePlanSortiesOk(distance, sorties int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireDistance(t, resp, data, distance)
+		require.Len(t, data["sorties"], sorties)
+	}
+}
+
+func SendRequestUpdateDosing(base, perMeter float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		body, err := json.Marshal(map[string]float64{"base": base, "per_meter": perMeter})
+		require.NoError(t, err)
+		return http.NewRequest("PUT", ApiUrl+"/estate/"+id+"/dosing", bytes.NewReader(body))
+	}
+}
+
+func ExpectUpdateDosingOk() ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func SendRequestUpdateElevation(grid string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("PUT", ApiUrl+"/estate/"+id+"/elevation", strings.NewReader(grid))
+	}
+}
+
+func ExpectUpdateElevationOk(plots int, min, max float64) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, float64(plots), data["plots"])
+		require.InDelta(t, min, data["min"].(float64), 1e-9)
+		require.InDelta(t, max, data["max"].(float64), 1e-9)
+	}
+}
+
+func SendRequestGetSprayPlan(capacity float64) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		return http.NewRequest("GET", fmt.Sprintf("%s/estate/%s/drone-plan/spray?tank_capacity=%g", ApiUrl, id, capacity), nil)
+	}
+}
+
+func ExpectGetSprayPlanOk(sorties int, litres float64, distance int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		RequireDistance(t, resp, data, distance)
+		require.Len(t, data["sorties"], sorties)
+		require.InDelta(t, litres, data["litres"].(float64), 1e-9)
+	}
+}
+
+func SendRequestNewMission(step int, maxDistance int) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		droneId := tc.Steps[step].Result["id"].(string)
+		body, err := json.Marshal(map[string]any{
+			"drone_id":   droneId,
+			"parameters": map[string]int{"max_distance": maxDistance},
+		})
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate/"+id+"/missions", bytes.NewReader(body))
+	}
+}
+
+func ExpectNewMissionOk(step int, distance int) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		RequireIsUUID(t, data["id"].(string))
+		require.Equal(t, tc.Steps[step].Result["id"], data["drone_id"])
+		require.Equal(t, "scheduled", data["status"])
+		require.Equal(t, distance, int(data["plan"].(map[string]any)["distance"].(float64)))
+	}
+}
+
+func SendRequestMissionTransition(step int, status string) RequestFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase) (*http.Request, error) {
+		id := tc.Steps[0].Result["id"].(string)
+		missionId := tc.Steps[step].Result["id"].(string)
+		body, err := json.Marshal(map[string]string{"status": status})
+		require.NoError(t, err)
+		return http.NewRequest("POST", ApiUrl+"/estate/"+id+"/missions/"+missionId+"/transitions", bytes.NewReader(body))
+	}
+}
+
+func ExpectMissionStatus(status string) ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, status, data["status"])
+	}
+}
+
+func ExpectConflict() ExpectFunc {
+	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+	}
+}
+
 func RequireReturnIsUUID(t *testing.T, resp *http.Response, data map[string]any) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 	RequireIsUUID(t, data["id"].(string))
@@ -260,6 +1004,12 @@ func RequireDistance(t *testing.T, resp *http.Response, data map[string]any, dis
 	require.Equal(t, distance, int(data["distance"].(float64)))
 }
 
+func RequireRest(t *testing.T, data map[string]any, x, y int) {
+	rest := data["rest"].(map[string]any)
+	require.Equal(t, x, int(rest["x"].(float64)))
+	require.Equal(t, y, int(rest["y"].(float64)))
+}
+
 func ExpectBadRequest() ExpectFunc {
 	return func(t *testing.T, ctx context.Context, tc *TestCase, resp *http.Response, data map[string]any) {
 		require.Equal(t, http.StatusBadRequest, resp.StatusCode)